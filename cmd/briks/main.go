@@ -1,24 +1,267 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"net"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/meszmate/briks/internal/ai"
 	"github.com/meszmate/briks/internal/config"
+	"github.com/meszmate/briks/internal/netplay"
+	"github.com/meszmate/briks/internal/replay"
+	"github.com/meszmate/briks/internal/server"
+	"github.com/meszmate/briks/internal/sim"
+	"github.com/meszmate/briks/internal/spectate"
+	"github.com/meszmate/briks/internal/tbp"
 	"github.com/meszmate/briks/internal/tui"
 )
 
+const usage = `Usage:
+  briks [--seed N] [--broadcast addr] [--bot command]
+                          play, optionally letting others watch
+  briks replay <file>     play back a saved replay
+  briks host [addr]       host an online versus match (default :7777)
+  briks join <addr>       join an online versus match
+  briks watch <addr>      watch a broadcast game
+  briks serve [--ssh addr]
+                          let others play over SSH (default :2222)
+  briks bot               run the built-in AI as a TBP bot on stdin/stdout
+  briks sim [--games N] [--seed S] [--bot name] [--json]
+                          play games with a bot, without the interface, and
+                          print statistics; see briks sim --help
+
+Flags:
+`
+
 func main() {
+	seed := flag.Uint64("seed", 0, "use a fixed piece sequence seed for every game")
+	broadcast := flag.String("broadcast", "", "let spectators watch on this address, e.g. "+spectate.DefaultAddr)
+	botCmd := flag.String("bot", "", `TBP bot command line to play the demo and versus against, or "builtin"`)
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if args := flag.Args(); len(args) > 0 && args[0] == "serve" {
+		serve(args[1:])
+		return
+	}
+	if args := flag.Args(); len(args) > 0 && args[0] == "sim" {
+		simulate(args[1:])
+		return
+	}
+	if args := flag.Args(); len(args) == 1 && args[0] == "bot" {
+		if err := tbp.Serve(os.Stdin, os.Stdout, ai.New(nil)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	cfg := config.Load()
 	keys := config.LoadKeyBindings()
 	hs := config.LoadHighScores()
+	bt := config.LoadBestTimes()
+
+	app := tui.NewApp(cfg, keys, hs, bt)
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			app.FixSeed(*seed)
+		}
+	})
 
-	app := tui.NewApp(cfg, keys, hs)
+	var session *netplay.Session
+	switch args := flag.Args(); {
+	case len(args) == 0:
+	case args[0] == "replay" && len(args) == 2:
+		r, err := replay.Load(args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		app.PlayReplay(r)
+	case args[0] == "host" && len(args) <= 2:
+		addr := netplay.DefaultAddr
+		if len(args) == 2 {
+			addr = args[1]
+		}
+		session = host(addr, cfg.VersusRounds)
+		app.StartNetplay(session)
+	case args[0] == "join" && len(args) == 2:
+		var err error
+		session, err = netplay.Dial(args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		app.StartNetplay(session)
+	case args[0] == "watch" && len(args) == 2:
+		w, err := spectate.Dial(args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		app.Watch(w, args[1])
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *broadcast != "" {
+		b, err := spectate.Listen(*broadcast)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer b.Close()
+		app.Broadcast(b)
+	}
+
+	var bot *tbp.Client
+	if *botCmd != "" {
+		var err error
+		if *botCmd == "builtin" {
+			bot, err = tbp.Builtin(ai.New(nil))
+		} else {
+			bot, err = tbp.Launch(*botCmd)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		app.SetBot(bot)
+	}
 
 	p := tea.NewProgram(app, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	_, err := p.Run()
+	tui.ResetKeyboard(os.Stdout)
+	if session != nil {
+		session.Close()
+	}
+	if bot != nil {
+		bot.Close()
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// host waits for an opponent to join a best-of-N match on addr.
+func host(addr string, bestOf int) *netplay.Session {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer ln.Close()
+	fmt.Printf("Waiting for an opponent on %s…\n", ln.Addr())
+	s, err := netplay.Accept(ln, bestOf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return s
+}
+
+// serve runs the SSH server until interrupted.
+func serve(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("ssh", server.DefaultAddr, "address to serve SSH on")
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir, err := config.Dir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("Serving briks over SSH on %s\n", *addr)
+	if err := server.New(filepath.Join(dir, "server")).ListenAndServe(ctx, *addr); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// simulate plays games with a bot and no interface, and prints how it did.
+func simulate(args []string) {
+	fs := flag.NewFlagSet("sim", flag.ExitOnError)
+	games := fs.Int("games", 100, "number of games to play")
+	seed := fs.Uint64("seed", 1, "seed of the first game; game i uses seed+i")
+	botName := fs.String("bot", "builtin", `"builtin", a JSON file of built-in AI weights, or a TBP bot command line`)
+	pieces := fs.Int("pieces", 1000, "end a game that reaches this many pieces")
+	workers := fs.Int("workers", runtime.GOMAXPROCS(0), "games the built-in AI plays at once")
+	asJSON := fs.Bool("json", false, "print the statistics as JSON")
+	fs.Parse(args)
+	if fs.NArg() > 0 || *games < 1 || *pieces < 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	name := *botName
+	var newPlayer func() (sim.Player, error)
+	switch {
+	case name == "builtin":
+		newPlayer = func() (sim.Player, error) { return sim.AI(ai.New(nil)), nil }
+	case strings.HasSuffix(name, ".json"):
+		data, err := os.ReadFile(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		// Weights missing from the file keep their defaults.
+		w := ai.DefaultWeights()
+		if err := json.Unmarshal(data, &w); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", name, err)
+			os.Exit(1)
+		}
+		newPlayer = func() (sim.Player, error) { return sim.AI(ai.New(w)), nil }
+	default:
+		c, err := tbp.Launch(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer c.Close()
+		name = c.Name
+		// The bot plays one game at a time.
+		*workers = 1
+		newPlayer = func() (sim.Player, error) { return sim.TBP(c), nil }
+	}
+
+	start := time.Now()
+	results, err := sim.Run(sim.Options{
+		Games:     *games,
+		Seed:      *seed,
+		MaxPieces: *pieces,
+		Workers:   *workers,
+	}, newPlayer)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	summary := sim.Summarize(name, *seed, results, time.Since(start))
+	if *asJSON {
+		data, _ := json.MarshalIndent(summary, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+	summary.WriteText(os.Stdout)
+}
@@ -0,0 +1,114 @@
+package ai
+
+import (
+	"github.com/meszmate/briks/internal/game"
+	"github.com/meszmate/briks/internal/replay"
+)
+
+// Bot picks placements with an Evaluator, looking one piece ahead into
+// the preview.
+type Bot struct {
+	Eval Evaluator
+}
+
+// New creates a bot using eval, or DefaultWeights if eval is nil.
+func New(eval Evaluator) *Bot {
+	if eval == nil {
+		eval = DefaultWeights()
+	}
+	return &Bot{Eval: eval}
+}
+
+// Candidates returns every placement of the current piece and, if hold
+// is available, of the piece holding would bring in.
+func Candidates(s State) []Placement {
+	if s.Current == nil {
+		return nil
+	}
+	candidates := Placements(s.Board, *s.Current)
+	if held, ok := holdPiece(s); ok {
+		spawn := game.Piece{Type: held, Rotation: game.Rot0, Pos: s.Board.SpawnPosition(held)}
+		for _, p := range Placements(s.Board, spawn) {
+			p.Hold = true
+			candidates = append(candidates, p)
+		}
+	}
+	return candidates
+}
+
+// holdPiece returns the piece holding would bring in.
+func holdPiece(s State) (game.PieceType, bool) {
+	switch {
+	case s.HoldUsed:
+		return 0, false
+	case s.Hold != nil:
+		return *s.Hold, *s.Hold != s.Current.Type
+	case len(s.Next) > 0:
+		return s.Next[0], true
+	default:
+		return 0, false
+	}
+}
+
+// Best returns the best placement in s, or false if the current piece
+// can't be placed at all.
+func (bot *Bot) Best(s State) (Placement, bool) {
+	var best Placement
+	bestScore, found := 0.0, false
+	for _, p := range Candidates(s) {
+		o := Simulate(s.Board, p)
+		score := bot.Eval.Evaluate(o) + bot.lookahead(o.Board, following(s, p))
+		if !found || score > bestScore {
+			best, bestScore, found = p, score, true
+		}
+	}
+	return best, found
+}
+
+// following returns the piece that comes up after p is placed, if the
+// preview shows it.
+func following(s State, p Placement) *game.PieceType {
+	next := s.Next
+	if p.Hold && s.Hold == nil && len(next) > 0 {
+		// Holding into an empty slot used up the first preview piece.
+		next = next[1:]
+	}
+	if len(next) == 0 {
+		return nil
+	}
+	return &next[0]
+}
+
+// lookahead rates a board by the best placement of the next piece on it.
+func (bot *Bot) lookahead(b *game.Board, next *game.PieceType) float64 {
+	if next == nil {
+		return 0
+	}
+	spawn := game.Piece{Type: *next, Rotation: game.Rot0, Pos: b.SpawnPosition(*next)}
+	best, found := 0.0, false
+	for _, p := range Placements(b, spawn) {
+		if score := bot.Eval.Evaluate(Simulate(b, p)); !found || score > best {
+			best, found = score, true
+		}
+	}
+	if !found {
+		// The next piece couldn't even spawn: avoid this at all costs.
+		return -1e9
+	}
+	return best
+}
+
+// Play makes the best placement in e, returning false if there was none.
+func (bot *Bot) Play(e *game.Engine) bool {
+	if e.State != game.StatePlaying {
+		return false
+	}
+	p, ok := bot.Best(StateOf(e))
+	if !ok {
+		return false
+	}
+	for _, action := range p.Actions() {
+		replay.Apply(e, action)
+	}
+	return true
+}
@@ -0,0 +1,182 @@
+package ai
+
+import (
+	"slices"
+
+	"github.com/meszmate/briks/internal/game"
+)
+
+// Outcome is a placement together with the board it leaves behind.
+type Outcome struct {
+	Placement
+	// Board is the board after the piece locked and full lines cleared.
+	Board *game.Board
+	Lines int
+}
+
+// Simulate locks a placement on a copy of b.
+func Simulate(b *game.Board, p Placement) Outcome {
+	after := b.Clone()
+	after.PlacePiece(&p.Piece)
+	lines, _ := after.ClearLines()
+	return Outcome{Placement: p, Board: after, Lines: lines}
+}
+
+// Evaluator rates outcomes; the bot plays the placement rated highest.
+type Evaluator interface {
+	Evaluate(o Outcome) float64
+}
+
+// EvaluatorFunc adapts a function to the Evaluator interface.
+type EvaluatorFunc func(o Outcome) float64
+
+// Evaluate calls f(o).
+func (f EvaluatorFunc) Evaluate(o Outcome) float64 {
+	return f(o)
+}
+
+// Weights is the built-in evaluator: a weighted sum of features of the
+// board left behind. Penalties have negative weights.
+type Weights struct {
+	// Height is per cell of the summed column heights.
+	Height float64 `json:"height"`
+	// Holes is per empty cell with a filled cell above it.
+	Holes float64 `json:"holes"`
+	// Bumpiness is per cell of height difference between neighbouring
+	// columns.
+	Bumpiness float64 `json:"bumpiness"`
+	// Wells is per cell of depth of columns lower than both neighbours,
+	// counted so that deep wells cost more than shallow ones. The
+	// deepest well is left for tetrises and costs nothing.
+	Wells float64 `json:"wells"`
+	// Clears rates clearing 0 to 4 lines.
+	Clears [5]float64 `json:"clears"`
+	// TSpin is per line cleared by a T-Spin.
+	TSpin float64 `json:"tspin"`
+	// TSlots is per line a T-Spin Double slot left on the board would
+	// clear.
+	TSlots float64 `json:"tslots"`
+}
+
+// DefaultWeights returns weights that survive long and take tetrises
+// and T-Spins when they come up.
+func DefaultWeights() Weights {
+	return Weights{
+		Height:    -0.15,
+		Holes:     -3.6,
+		Bumpiness: -0.18,
+		Wells:     -0.1,
+		Clears:    [5]float64{0, -3.5, -3, -1.5, 9},
+		TSpin:     2.5,
+		TSlots:    0.8,
+	}
+}
+
+// Evaluate rates an outcome by the weighted features.
+func (w Weights) Evaluate(o Outcome) float64 {
+	heights := columnHeights(o.Board)
+
+	score := w.Clears[min(o.Lines, 4)]
+	if o.TSpin == game.TSpinFull {
+		score += w.TSpin * float64(o.Lines)
+	}
+
+	well, wellDepthMax := -1, 0
+	for c := range heights {
+		if d := wellDepth(o.Board, heights, c); d > wellDepthMax {
+			well, wellDepthMax = c, d
+		}
+	}
+	prev := -1
+	for c, h := range heights {
+		score += w.Height * float64(h)
+		if c == well {
+			continue
+		}
+		if prev >= 0 {
+			score += w.Bumpiness * float64(abs(h-heights[prev]))
+		}
+		prev = c
+		if d := wellDepth(o.Board, heights, c); d > 0 {
+			score += w.Wells * float64(d*(d+1)/2)
+		}
+	}
+	score += w.Holes * float64(holes(o.Board, heights))
+	if w.TSlots != 0 {
+		score += w.TSlots * float64(tslots(o.Board, heights))
+	}
+	return score
+}
+
+// columnHeights returns how high each column is filled.
+func columnHeights(b *game.Board) []int {
+	heights := make([]int, b.Width)
+	for c := range heights {
+		for r := 0; r < b.Rows(); r++ {
+			if b.Cells[r][c] != game.Empty {
+				heights[c] = b.Rows() - r
+				break
+			}
+		}
+	}
+	return heights
+}
+
+// holes counts empty cells below the top of their column.
+func holes(b *game.Board, heights []int) int {
+	n := 0
+	for c, h := range heights {
+		for r := b.Rows() - h; r < b.Rows(); r++ {
+			if b.Cells[r][c] == game.Empty {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// wellDepth returns how far column c of b lies below both neighbours;
+// walls count as infinitely high.
+func wellDepth(b *game.Board, heights []int, c int) int {
+	left, right := b.Rows(), b.Rows()
+	if c > 0 {
+		left = heights[c-1]
+	}
+	if c < len(heights)-1 {
+		right = heights[c+1]
+	}
+	return max(min(left, right)-heights[c], 0)
+}
+
+// tslots sums the lines cleared by the T-Spin Doubles the board is set
+// up for: places a T pointing down rests with three corners covered.
+func tslots(b *game.Board, heights []int) int {
+	// A slot needs a roof, so it can't start above the highest column.
+	top := b.Rows() - slices.Max(heights)
+	down := game.TDown(b.System)
+	n := 0
+	for row := max(top-1, 0); row < b.Rows()-2; row++ {
+		for col := -1; col < b.Width-1; col++ {
+			t := game.Piece{Type: game.PieceT, Rotation: down, Pos: game.Position{Row: row, Col: col}}
+			if !b.ValidPosition(&t) || b.TSpin(&t, 0) != game.TSpinFull {
+				continue
+			}
+			below := t
+			below.Pos.Row++
+			if b.ValidPosition(&below) {
+				continue
+			}
+			if lines := Simulate(b, Placement{Piece: t}).Lines; lines >= 2 {
+				n += lines
+			}
+		}
+	}
+	return n
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,211 @@
+// Package ai plays briks. It finds every placement the current piece,
+// or the held one, can reach and picks the best one with an Evaluator.
+package ai
+
+import (
+	"github.com/meszmate/briks/internal/config"
+	"github.com/meszmate/briks/internal/game"
+)
+
+// State is what the bot sees of a game.
+type State struct {
+	Board    *game.Board
+	Current  *game.Piece
+	Hold     *game.PieceType
+	HoldUsed bool
+	Next     []game.PieceType
+}
+
+// StateOf returns the state of a game in play.
+func StateOf(e *game.Engine) State {
+	return State{
+		Board:    e.Board,
+		Current:  e.Current,
+		Hold:     e.HoldPiece,
+		HoldUsed: e.HoldUsed,
+		Next:     e.NextPieces(),
+	}
+}
+
+// Placement is one way to lock a piece: the inputs that take it from
+// where it is to where a hard drop locks it.
+type Placement struct {
+	// Piece is the piece where it locks.
+	Piece game.Piece
+	// Hold is set when the piece is the one swapped in by holding first.
+	Hold bool
+	// Inputs are the moves, rotations and soft drops to make before the
+	// hard drop.
+	Inputs []config.Action
+	TSpin  game.TSpinKind
+}
+
+// Actions returns every input of the placement in order: the hold, the
+// moves and the final hard drop.
+func (p Placement) Actions() []config.Action {
+	var actions []config.Action
+	if p.Hold {
+		actions = append(actions, config.ActionHold)
+	}
+	actions = append(actions, p.Inputs...)
+	return append(actions, config.ActionHardDrop)
+}
+
+// node is a position the piece can reach, with the T-Spin it would score
+// if it locked there.
+type node struct {
+	piece game.Piece
+	spin  game.TSpinKind
+}
+
+// step is a node reached during the search and how it was reached.
+type step struct {
+	node
+	parent int
+	// action was taken count times to get here from the parent.
+	action config.Action
+	count  int
+}
+
+// Placements returns every placement of p reachable on b by moves,
+// rotations with wall kicks, 180° turns and soft drops, including tucks
+// and spins under overhangs. Each comes with a shortest input sequence;
+// placements that lock the same cells for the same T-Spin are returned
+// once.
+func Placements(b *game.Board, p game.Piece) []Placement {
+	if !b.ValidPosition(&p) {
+		return nil
+	}
+
+	steps := []step{{node: node{piece: p}, parent: -1}}
+	seen := newNodeSet(b)
+	seen.add(steps[0].node)
+	visit := func(parent int, n node, action config.Action, count int) {
+		if seen.add(n) {
+			steps = append(steps, step{node: n, parent: parent, action: action, count: count})
+		}
+	}
+
+	var placements []Placement
+	locked := make(map[[5]game.Position]bool)
+	for i := 0; i < len(steps); i++ {
+		cur := steps[i].piece
+
+		for _, move := range [...]struct {
+			action config.Action
+			col    int
+		}{{config.ActionMoveLeft, -1}, {config.ActionMoveRight, 1}} {
+			next := cur
+			next.Pos.Col += move.col
+			if b.ValidPosition(&next) {
+				visit(i, node{piece: next}, move.action, 1)
+			}
+		}
+
+		for _, turn := range [...]struct {
+			action config.Action
+			to     game.Rotation
+		}{
+			{config.ActionRotateCW, cur.Rotation.CW()},
+			{config.ActionRotateCCW, cur.Rotation.CCW()},
+			{config.ActionRotate180, cur.Rotation.Half()},
+		} {
+			if next, kick, ok := b.Rotate(&cur, turn.to); ok {
+				kick = game.SpinKick(kick, turn.to == cur.Rotation.Half())
+				visit(i, node{piece: next, spin: b.TSpin(&next, kick)}, turn.action, 1)
+			}
+		}
+
+		// Dropping all the way counts as one step, so that tucks, which
+		// drop and then move, cost no more than plain drops.
+		dropped, rows := cur, 0
+		for {
+			below := dropped
+			below.Pos.Row++
+			if !b.ValidPosition(&below) {
+				break
+			}
+			dropped, rows = below, rows+1
+		}
+		if rows > 0 {
+			visit(i, node{piece: dropped}, config.ActionSoftDrop, rows)
+			continue
+		}
+
+		// The piece rests here: a hard drop locks it.
+		key := lockKey(b, cur, steps[i].spin)
+		if !locked[key] {
+			locked[key] = true
+			placements = append(placements, Placement{
+				Piece:  cur,
+				Inputs: path(steps, i),
+				TSpin:  steps[i].spin,
+			})
+		}
+	}
+	return placements
+}
+
+// nodeSet is the set of nodes seen by the search. The search is the hot
+// loop of the bot, which is why this isn't a map.
+type nodeSet struct {
+	rows, cols int
+	seen       []bool
+}
+
+// newNodeSet creates an empty set for the nodes of b. A piece's position
+// is the top-left of its 4x4 box, so it can stick out past the top and
+// left edges by up to three cells.
+func newNodeSet(b *game.Board) *nodeSet {
+	rows, cols := b.Rows()+3, b.Width+3
+	return &nodeSet{rows: rows, cols: cols, seen: make([]bool, 4*rows*cols*3)}
+}
+
+// add adds n to the set, returning false if it was already there.
+func (s *nodeSet) add(n node) bool {
+	i := ((int(n.piece.Rotation)*s.rows+n.piece.Pos.Row+3)*s.cols+n.piece.Pos.Col+3)*3 + int(n.spin)
+	if s.seen[i] {
+		return false
+	}
+	s.seen[i] = true
+	return true
+}
+
+// lockKey identifies what locking a piece does: the cells it fills and
+// the T-Spin it scores. Different rotations of S, Z, I and O can fill
+// the same cells.
+func lockKey(b *game.Board, p game.Piece, spin game.TSpinKind) [5]game.Position {
+	var key [5]game.Position
+	copy(key[:4], b.PieceCells(&p))
+	// Sort the four cells so the key doesn't depend on their order.
+	for i := 1; i < 4; i++ {
+		for j := i; j > 0 && less(key[j], key[j-1]); j-- {
+			key[j], key[j-1] = key[j-1], key[j]
+		}
+	}
+	key[4] = game.Position{Row: int(spin)}
+	return key
+}
+
+func less(a, b game.Position) bool {
+	return a.Row < b.Row || (a.Row == b.Row && a.Col < b.Col)
+}
+
+// path returns the inputs that lead to steps[i]. Trailing soft drops are
+// left out, since the hard drop covers them.
+func path(steps []step, i int) []config.Action {
+	var rev []config.Action
+	for ; steps[i].parent >= 0; i = steps[i].parent {
+		for range steps[i].count {
+			rev = append(rev, steps[i].action)
+		}
+	}
+	for len(rev) > 0 && rev[0] == config.ActionSoftDrop {
+		rev = rev[1:]
+	}
+	inputs := make([]config.Action, len(rev))
+	for j, a := range rev {
+		inputs[len(rev)-1-j] = a
+	}
+	return inputs
+}
@@ -0,0 +1,270 @@
+package ai
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/meszmate/briks/internal/config"
+	"github.com/meszmate/briks/internal/game"
+	"github.com/meszmate/briks/internal/replay"
+)
+
+// boardOf returns a standard board whose bottom rows are rows, top first,
+// with '#' for a filled cell.
+func boardOf(rows ...string) *game.Board {
+	b := game.NewBoard(game.DefaultWidth, game.DefaultHeight)
+	top := b.Rows() - len(rows)
+	for r, row := range rows {
+		for c, ch := range row {
+			if ch == '#' {
+				b.Cells[top+r][c] = game.ColorGarbage
+			}
+		}
+	}
+	return b
+}
+
+// spawn returns a piece of type pt where it spawns on b.
+func spawn(b *game.Board, pt game.PieceType) game.Piece {
+	return game.Piece{Type: pt, Rotation: game.Rot0, Pos: b.SpawnPosition(pt)}
+}
+
+// bottom returns the position of a cell counted from the bottom row of
+// b, which is row 0.
+func bottom(b *game.Board, row, col int) game.Position {
+	return game.Position{Row: b.Rows() - 1 - row, Col: col}
+}
+
+// search returns the placement of p on b that locks exactly cells.
+func search(b *game.Board, p game.Piece, cells []game.Position) (Placement, bool) {
+	for _, pl := range Placements(b, p) {
+		key := lockKey(b, pl.Piece, game.TSpinNone)
+		if slices.Equal(key[:4], sorted(cells)) {
+			return pl, true
+		}
+	}
+	return Placement{}, false
+}
+
+// find is search for a placement that must be there.
+func find(t *testing.T, b *game.Board, p game.Piece, cells ...game.Position) Placement {
+	t.Helper()
+	pl, ok := search(b, p, cells)
+	if !ok {
+		t.Fatalf("no placement locks %v", cells)
+	}
+	return pl
+}
+
+func sorted(cells []game.Position) []game.Position {
+	s := slices.Clone(cells)
+	slices.SortFunc(s, func(a, b game.Position) int {
+		switch {
+		case less(a, b):
+			return -1
+		case less(b, a):
+			return 1
+		}
+		return 0
+	})
+	return s
+}
+
+// kickless is SRS without wall kicks: pieces only turn in place.
+type kickless struct{ game.RotationSystem }
+
+func (kickless) Kicks(*game.Board, *game.Piece, game.Rotation) []game.Position {
+	return []game.Position{{}}
+}
+
+// engineAt returns an engine playing p on a copy of b.
+func engineAt(b *game.Board, p game.Piece) *game.Engine {
+	e := game.NewEngine(game.MarathonOptions(1, 5, 1))
+	e.Board = b.Clone()
+	e.Current = &p
+	return e
+}
+
+// play makes inputs in e, failing if any of them does nothing.
+func play(t *testing.T, e *game.Engine, inputs []config.Action) {
+	t.Helper()
+	for _, a := range inputs {
+		if !replay.Apply(e, a) {
+			t.Fatalf("%s did nothing in %v", a, inputs)
+		}
+	}
+}
+
+// lock plays a placement of p on a copy of b in a real engine and
+// returns the engine once the piece has locked.
+func lock(t *testing.T, b *game.Board, p game.Piece, pl Placement) *game.Engine {
+	t.Helper()
+	e := engineAt(b, p)
+	play(t, e, pl.Inputs)
+	e.HardDrop()
+	if e.PiecesPlaced != 1 {
+		t.Fatalf("playing %v didn't lock the piece", pl.Actions())
+	}
+	return e
+}
+
+func TestPlacementsTuck(t *testing.T) {
+	// A roof over the bottom left: a flat I only gets under it by
+	// dropping beside it and sliding in.
+	b := boardOf(
+		"###.......",
+		"..........",
+	)
+	p := spawn(b, game.PieceI)
+	pl := find(t, b, p, bottom(b, 0, 0), bottom(b, 0, 1), bottom(b, 0, 2), bottom(b, 0, 3))
+
+	drop := slices.Index(pl.Inputs, config.ActionSoftDrop)
+	if drop < 0 || !slices.Contains(pl.Inputs[drop:], config.ActionMoveLeft) {
+		t.Errorf("tuck inputs %v don't drop and then move left", pl.Inputs)
+	}
+	e := lock(t, b, p, pl)
+	for c := range 4 {
+		if e.Board.IsEmpty(bottom(b, 0, c)) {
+			t.Errorf("bottom row column %d is empty after the tuck", c)
+		}
+	}
+}
+
+func TestPlacementsTSpins(t *testing.T) {
+	tests := []struct {
+		name  string
+		board []string
+		// cells are the T's cells as (row from the bottom, column).
+		cells [4][2]int
+		// kick is the kick test the last turn must use; -1 is any but
+		// the first.
+		kick  int
+		clear game.LineClearType
+	}{
+		{
+			// The overhang at the top left keeps a T from dropping or
+			// turning in place into the slot; only a kicked turn gets it
+			// in.
+			name: "TSD by kick",
+			board: []string{
+				"###.......",
+				"#.........",
+				"##..#.....",
+				"#...######",
+				"##.#######",
+			},
+			cells: [4][2]int{{1, 1}, {1, 2}, {1, 3}, {0, 2}},
+			kick:  -1,
+			clear: game.ClearTSpinDouble,
+		},
+		{
+			// The T rests flat on the shelf to the right of the well and
+			// turns clockwise into it. The first four kick tests are all
+			// blocked, so only the fifth, one left and two down, fits.
+			name: "TST by kick 4",
+			board: []string{
+				"####......",
+				"###.......",
+				"###.######",
+				"###..#####",
+				"###.######",
+			},
+			cells: [4][2]int{{2, 3}, {1, 3}, {1, 4}, {0, 3}},
+			kick:  4,
+			clear: game.ClearTSpinTriple,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := boardOf(tt.board...)
+			var cells []game.Position
+			for _, c := range tt.cells {
+				cells = append(cells, bottom(b, c[0], c[1]))
+			}
+			p := spawn(b, game.PieceT)
+			pl := find(t, b, p, cells...)
+			if pl.TSpin != game.TSpinFull {
+				t.Errorf("TSpin = %v, want TSpinFull", pl.TSpin)
+			}
+
+			// Play up to the last turn and see which kick it takes.
+			e := engineAt(b, p)
+			play(t, e, pl.Inputs[:len(pl.Inputs)-1])
+			to := e.Current.Rotation.CW()
+			switch pl.Inputs[len(pl.Inputs)-1] {
+			case config.ActionRotateCW:
+			case config.ActionRotateCCW:
+				to = e.Current.Rotation.CCW()
+			default:
+				t.Fatalf("inputs %v don't end with a quarter turn", pl.Inputs)
+			}
+			_, kick, ok := b.Rotate(e.Current, to)
+			if !ok || kick == 0 || tt.kick >= 0 && kick != tt.kick {
+				t.Errorf("last turn used kick %d (ok %v), want %d", kick, ok, tt.kick)
+			}
+			if e := lock(t, b, p, pl); e.LastClear.Type != tt.clear {
+				t.Errorf("locking it scored %v, want %v", e.LastClear.Type, tt.clear)
+			}
+
+			// Without kicks there is no way in.
+			nk := b.Clone()
+			nk.System = kickless{game.SRS}
+			if pl, ok := search(nk, p, cells); ok {
+				t.Errorf("reached the slot without kicks by %v", pl.Inputs)
+			}
+		})
+	}
+}
+
+func TestPlacementsDeduplicated(t *testing.T) {
+	// On an empty board each distinct resting place is listed once,
+	// however many rotations reach it.
+	tests := []struct {
+		piece game.PieceType
+		want  int
+	}{
+		{game.PieceO, 9},
+		{game.PieceI, 7 + 10},
+		{game.PieceS, 8 + 9},
+		{game.PieceZ, 8 + 9},
+		{game.PieceT, 8 + 8 + 9 + 9},
+	}
+	for _, tt := range tests {
+		b := boardOf()
+		placements := Placements(b, spawn(b, tt.piece))
+		keys := make(map[[5]game.Position]bool)
+		for _, pl := range placements {
+			keys[lockKey(b, pl.Piece, pl.TSpin)] = true
+		}
+		if len(placements) != tt.want || len(keys) != tt.want {
+			t.Errorf("piece %d: %d placements with %d distinct locks, want %d",
+				tt.piece, len(placements), len(keys), tt.want)
+		}
+	}
+}
+
+func TestBestWithoutMoves(t *testing.T) {
+	b := boardOf(
+		"##########",
+		"##########",
+	)
+	// A piece buried in filled cells can go nowhere, and with hold used
+	// there is nothing else to play.
+	p := game.Piece{Type: game.PieceT, Rotation: game.Rot0, Pos: bottom(b, 1, 0)}
+	s := State{Board: b, Current: &p, HoldUsed: true, Next: []game.PieceType{game.PieceI}}
+	if pl, ok := New(nil).Best(s); ok {
+		t.Errorf("Best found %v for a piece that can't move", pl)
+	}
+
+	s.Current = nil
+	if _, ok := New(nil).Best(s); ok {
+		t.Error("Best found a placement without a current piece")
+	}
+
+	// With hold free, the next piece can still be played.
+	s.Current = &p
+	s.HoldUsed = false
+	if pl, ok := New(nil).Best(s); !ok || !pl.Hold {
+		t.Errorf("Best = %v, %v; want a placement of the held piece", pl, ok)
+	}
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+const bestTimesFile = "besttimes.json"
+
+const MaxBestTimes = 10
+
+// BestTime is a single entry in a time-based leaderboard.
+type BestTime struct {
+	Time   time.Duration `json:"time"`
+	Pieces int           `json:"pieces"`
+	Seed   uint64        `json:"seed,omitempty"`
+	Date   time.Time     `json:"date"`
+	// Name is the player, on a server shared by several.
+	Name string `json:"name,omitempty"`
+	// Hinted marks a run played with placement hints; see HighScore.
+	Hinted bool `json:"hinted,omitempty"`
+	// Practice marks a practice run, which is not ranked; see HighScore.
+	Practice bool `json:"practice,omitempty"`
+}
+
+func (t BestTime) tier() int {
+	return standing(t.Hinted, t.Practice)
+}
+
+// BestTimes holds time-based leaderboards, fastest first, keyed by board
+// name such as "sprint-40". The methods are safe for concurrent use.
+type BestTimes struct {
+	Boards map[string][]BestTime `json:"boards"`
+
+	mu sync.Mutex
+	// dir is where the times are saved; empty means Dir.
+	dir string
+}
+
+// SprintBoard returns the leaderboard name for a sprint line goal.
+func SprintBoard(lines int) string {
+	return fmt.Sprintf("sprint-%d", lines)
+}
+
+// CheeseBoard returns the leaderboard name for a cheese race garbage goal.
+func CheeseBoard(lines int) string {
+	return fmt.Sprintf("cheese-%d", lines)
+}
+
+// LoadBestTimes reads the time leaderboards from disk.
+func LoadBestTimes() *BestTimes {
+	return LoadBestTimesFrom("")
+}
+
+// LoadBestTimesFrom reads the time leaderboards from a data directory
+// other than Dir. Save writes them back there.
+func LoadBestTimesFrom(dir string) *BestTimes {
+	bt := &BestTimes{Boards: map[string][]BestTime{}, dir: dir}
+
+	path, err := dataPath(dir, bestTimesFile)
+	if err != nil {
+		return bt
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return bt
+	}
+
+	if err := json.Unmarshal(data, bt); err != nil || bt.Boards == nil {
+		return &BestTimes{Boards: map[string][]BestTime{}, dir: dir}
+	}
+
+	return bt
+}
+
+// Save writes the time leaderboards to disk.
+func (bt *BestTimes) Save() error {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+
+	path, err := dataPath(bt.dir, bestTimesFile)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(bt, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// Board returns the entries of a board. The returned slice is never
+// modified afterwards.
+func (bt *BestTimes) Board(board string) []BestTime {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+	return bt.Boards[board]
+}
+
+// Add inserts a time into a board and returns its rank (1-based), or 0 if
+// it didn't make the list.
+func (bt *BestTimes) Add(board string, entry BestTime) int {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+
+	// Sort a copy, since readers may still hold the old board.
+	times := append(slices.Clone(bt.Boards[board]), entry)
+	sort.SliceStable(times, func(i, j int) bool {
+		if times[i].tier() != times[j].tier() {
+			return times[i].tier() < times[j].tier()
+		}
+		return times[i].Time < times[j].Time
+	})
+
+	if len(times) > MaxBestTimes {
+		times = times[:MaxBestTimes]
+	}
+	bt.Boards[board] = times
+
+	for i, t := range times {
+		if t.Time == entry.Time && t.Date.Equal(entry.Date) {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// IsBestTime checks if a time would make a board's top list.
+func (bt *BestTimes) IsBestTime(board string, t time.Duration) bool {
+	return bt.IsRanked(board, BestTime{Time: t})
+}
+
+// IsRanked checks if an entry would make a board's top list, taking into
+// account whether it was hinted or practice.
+func (bt *BestTimes) IsRanked(board string, entry BestTime) bool {
+	times := bt.Board(board)
+	if len(times) < MaxBestTimes {
+		return true
+	}
+	last := times[len(times)-1]
+	if entry.tier() != last.tier() {
+		return entry.tier() < last.tier()
+	}
+	return entry.Time < last.Time
+}
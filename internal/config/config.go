@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+
+	"github.com/meszmate/briks/internal/game"
 )
 
 const configDir = ".config/briks"
@@ -15,9 +17,25 @@ type Config struct {
 	StartLevel   int    `json:"start_level"`
 	GhostPiece   bool   `json:"ghost_piece"`
 	ShowGrid     bool   `json:"show_grid"`
+	Hints        bool   `json:"hints"`      // placement hints; hinted games rank last
+	Practice     bool   `json:"practice"`   // undo and save states; practice games are unranked
+	UndoDepth    int    `json:"undo_depth"` // pieces practice undo can take back
 	PreviewCount int    `json:"preview_count"`
 	DAS          int    `json:"das"` // Delayed Auto Shift in ms
 	ARR          int    `json:"arr"` // Auto Repeat Rate in ms
+	SprintLines  int    `json:"sprint_lines"`
+	UltraSeconds int    `json:"ultra_seconds"`
+	CheeseLines  int    `json:"cheese_lines"`
+	BoardWidth   int    `json:"board_width"`
+	BoardHeight  int    `json:"board_height"`
+	Rotation     string `json:"rotation"`      // rotation system name
+	VersusRounds int    `json:"versus_rounds"` // best of N
+	P1Keys       string `json:"p1_keys"`       // versus key profile
+	P2Keys       string `json:"p2_keys"`
+
+	// dir is where the configuration was loaded from and is saved to;
+	// empty means Dir.
+	dir string
 }
 
 // DefaultConfig returns the default configuration.
@@ -27,35 +45,79 @@ func DefaultConfig() *Config {
 		StartLevel:   1,
 		GhostPiece:   true,
 		ShowGrid:     false,
+		Hints:        false,
+		Practice:     false,
+		UndoDepth:    10,
 		PreviewCount: 5,
+		BoardWidth:   game.DefaultWidth,
+		BoardHeight:  game.DefaultHeight,
+		Rotation:     game.SRS.Name(),
 		DAS:          170,
 		ARR:          50,
+		SprintLines:  40,
+		UltraSeconds: 120,
+		CheeseLines:  10,
+		VersusRounds: 3,
+		P1Keys:       ProfileWASD,
+		P2Keys:       ProfileArrows,
 	}
 }
 
-func configPath() (string, error) {
+// Dir returns the directory holding all persistent briks data.
+func Dir() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(home, configDir, configFile), nil
+	return filepath.Join(home, configDir), nil
+}
+
+// dataPath returns the path of a data file in dir, or in Dir if dir is
+// empty.
+func dataPath(dir, file string) (string, error) {
+	if dir == "" {
+		var err error
+		if dir, err = Dir(); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, file), nil
+}
+
+// DataDir returns the directory the configuration is saved in, which
+// holds the rest of the player's own data too.
+func (c *Config) DataDir() (string, error) {
+	if c.dir != "" {
+		return c.dir, nil
+	}
+	return Dir()
 }
 
 // Load reads configuration from disk, falling back to defaults.
 func Load() *Config {
-	path, err := configPath()
+	return LoadFrom("")
+}
+
+// LoadFrom reads configuration from a data directory other than Dir,
+// such as a player's own on a shared server. Save writes it back there.
+func LoadFrom(dir string) *Config {
+	cfg := DefaultConfig()
+	cfg.dir = dir
+
+	path, err := dataPath(dir, configFile)
 	if err != nil {
-		return DefaultConfig()
+		return cfg
 	}
 
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return DefaultConfig()
+		return cfg
 	}
 
-	cfg := DefaultConfig()
 	if err := json.Unmarshal(data, cfg); err != nil {
-		return DefaultConfig()
+		cfg = DefaultConfig()
+		cfg.dir = dir
+		return cfg
 	}
 
 	cfg.validate()
@@ -64,7 +126,7 @@ func Load() *Config {
 
 // Save writes the configuration to disk.
 func (c *Config) Save() error {
-	path, err := configPath()
+	path, err := dataPath(c.dir, configFile)
 	if err != nil {
 		return err
 	}
@@ -94,6 +156,18 @@ func (c *Config) validate() {
 	if c.PreviewCount > 5 {
 		c.PreviewCount = 5
 	}
+	c.BoardWidth, c.BoardHeight = game.ClampBoardSize(c.BoardWidth, c.BoardHeight)
+	if rs, ok := game.RotationSystemNamed(c.Rotation); ok {
+		c.Rotation = rs.Name()
+	} else {
+		c.Rotation = game.SRS.Name()
+	}
+	if c.UndoDepth < 1 {
+		c.UndoDepth = 1
+	}
+	if c.UndoDepth > 50 {
+		c.UndoDepth = 50
+	}
 	if c.DAS < 50 {
 		c.DAS = 50
 	}
@@ -106,4 +180,22 @@ func (c *Config) validate() {
 	if c.ARR > 200 {
 		c.ARR = 200
 	}
+	if c.SprintLines != 20 && c.SprintLines != 40 && c.SprintLines != 100 {
+		c.SprintLines = 40
+	}
+	if c.UltraSeconds != 60 && c.UltraSeconds != 120 && c.UltraSeconds != 180 {
+		c.UltraSeconds = 120
+	}
+	if c.CheeseLines != 10 && c.CheeseLines != 18 && c.CheeseLines != 100 {
+		c.CheeseLines = 10
+	}
+	if c.VersusRounds != 1 && c.VersusRounds != 3 && c.VersusRounds != 5 {
+		c.VersusRounds = 3
+	}
+	if !isKeyProfile(c.P1Keys) {
+		c.P1Keys = ProfileWASD
+	}
+	if !isKeyProfile(c.P2Keys) {
+		c.P2Keys = ProfileArrows
+	}
 }
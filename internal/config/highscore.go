@@ -2,10 +2,16 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/meszmate/briks/internal/game"
 )
 
 const highscoreFile = "highscores.json"
@@ -18,37 +24,95 @@ type HighScore struct {
 	Level  int       `json:"level"`
 	Lines  int       `json:"lines"`
 	Pieces int       `json:"pieces"`
+	Seed   uint64    `json:"seed,omitempty"`
 	Date   time.Time `json:"date"`
+	// Name is the player, on a server shared by several.
+	Name string `json:"name,omitempty"`
+	// Hinted marks a game played with placement hints. Hinted scores rank
+	// below all others, so they never push an unassisted game off a board.
+	Hinted bool `json:"hinted,omitempty"`
+	// Practice marks a game in which pieces were undone or states loaded.
+	// Practice games are not ranked: they are listed for reference below
+	// all others.
+	Practice bool `json:"practice,omitempty"`
+}
+
+func (s HighScore) tier() int {
+	return standing(s.Hinted, s.Practice)
 }
 
-// HighScores manages the top scores list.
+// standing is the tier a board entry ranks in by how it was played:
+// unassisted games first, then hinted ones, then practice games.
+func standing(hinted, practice bool) int {
+	switch {
+	case practice:
+		return 2
+	case hinted:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// HighScores manages the top scores list. Scores holds the Marathon
+// table; modes with their own tables keep them in Boards. The methods
+// are safe for concurrent use, so one list can be shared by all players
+// of a server.
 type HighScores struct {
-	Scores []HighScore `json:"scores"`
+	Scores []HighScore            `json:"scores"`
+	Boards map[string][]HighScore `json:"boards,omitempty"`
+
+	mu sync.Mutex
+	// dir is where the scores are saved; empty means Dir.
+	dir string
 }
 
-func highscorePath() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
+// UltraBoard returns the board name for an Ultra time limit in seconds.
+func UltraBoard(seconds int) string {
+	return fmt.Sprintf("ultra-%d", seconds)
+}
+
+// VariantBoard returns the name of a board for games played on a board
+// of width x height with the named rotation system. The standard size
+// with SRS keeps the plain name; the empty name is the Marathon table.
+func VariantBoard(board string, width, height int, rotation string) string {
+	var variant string
+	if width != game.DefaultWidth || height != game.DefaultHeight {
+		variant += fmt.Sprintf("-%dx%d", width, height)
+	}
+	if rotation != game.SRS.Name() {
+		variant += "-" + strings.ToLower(rotation)
+	}
+	if variant == "" {
+		return board
+	}
+	if board == "" {
+		board = "marathon"
 	}
-	return filepath.Join(home, configDir, highscoreFile), nil
+	return board + variant
 }
 
 // LoadHighScores reads high scores from disk.
 func LoadHighScores() *HighScores {
-	path, err := highscorePath()
+	return LoadHighScoresFrom("")
+}
+
+// LoadHighScoresFrom reads high scores from a data directory other than
+// Dir. Save writes them back there.
+func LoadHighScoresFrom(dir string) *HighScores {
+	path, err := dataPath(dir, highscoreFile)
 	if err != nil {
-		return &HighScores{}
+		return &HighScores{dir: dir}
 	}
 
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return &HighScores{}
+		return &HighScores{dir: dir}
 	}
 
-	hs := &HighScores{}
+	hs := &HighScores{dir: dir}
 	if err := json.Unmarshal(data, hs); err != nil {
-		return &HighScores{}
+		return &HighScores{dir: dir}
 	}
 
 	return hs
@@ -56,7 +120,10 @@ func LoadHighScores() *HighScores {
 
 // Save writes high scores to disk.
 func (hs *HighScores) Save() error {
-	path, err := highscorePath()
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	path, err := dataPath(hs.dir, highscoreFile)
 	if err != nil {
 		return err
 	}
@@ -73,18 +140,58 @@ func (hs *HighScores) Save() error {
 	return os.WriteFile(path, data, 0644)
 }
 
+// Board returns the entries of a board; the empty name is Scores. The
+// returned slice is never modified afterwards.
+func (hs *HighScores) Board(board string) []HighScore {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	return hs.board(board)
+}
+
+func (hs *HighScores) board(board string) []HighScore {
+	if board == "" {
+		return hs.Scores
+	}
+	return hs.Boards[board]
+}
+
+func (hs *HighScores) setBoard(board string, scores []HighScore) {
+	if board == "" {
+		hs.Scores = scores
+		return
+	}
+	if hs.Boards == nil {
+		hs.Boards = make(map[string][]HighScore)
+	}
+	hs.Boards[board] = scores
+}
+
 // Add inserts a score and returns its rank (1-based), or 0 if it didn't make the list.
 func (hs *HighScores) Add(score HighScore) int {
-	hs.Scores = append(hs.Scores, score)
-	sort.Slice(hs.Scores, func(i, j int) bool {
-		return hs.Scores[i].Score > hs.Scores[j].Score
+	return hs.AddTo("", score)
+}
+
+// AddTo inserts a score into a board and returns its rank (1-based), or 0
+// if it didn't make the list.
+func (hs *HighScores) AddTo(board string, score HighScore) int {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	// Sort a copy, since readers may still hold the old board.
+	scores := append(slices.Clone(hs.board(board)), score)
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].tier() != scores[j].tier() {
+			return scores[i].tier() < scores[j].tier()
+		}
+		return scores[i].Score > scores[j].Score
 	})
 
-	if len(hs.Scores) > MaxHighScores {
-		hs.Scores = hs.Scores[:MaxHighScores]
+	if len(scores) > MaxHighScores {
+		scores = scores[:MaxHighScores]
 	}
+	hs.setBoard(board, scores)
 
-	for i, s := range hs.Scores {
+	for i, s := range scores {
 		if s.Score == score.Score && s.Date.Equal(score.Date) {
 			return i + 1
 		}
@@ -95,8 +202,24 @@ func (hs *HighScores) Add(score HighScore) int {
 
 // IsHighScore checks if a score would make the top list.
 func (hs *HighScores) IsHighScore(score int) bool {
-	if len(hs.Scores) < MaxHighScores {
+	return hs.IsHighScoreOn("", score)
+}
+
+// IsHighScoreOn checks if a score would make the top list of a board.
+func (hs *HighScores) IsHighScoreOn(board string, score int) bool {
+	return hs.IsRankedOn(board, HighScore{Score: score})
+}
+
+// IsRankedOn checks if an entry would make the top list of a board,
+// taking into account whether it was hinted or practice.
+func (hs *HighScores) IsRankedOn(board string, entry HighScore) bool {
+	scores := hs.Board(board)
+	if len(scores) < MaxHighScores {
 		return true
 	}
-	return score > hs.Scores[len(hs.Scores)-1].Score
+	last := scores[len(scores)-1]
+	if entry.tier() != last.tier() {
+		return entry.tier() < last.tier()
+	}
+	return entry.Score > last.Score
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"slices"
 )
 
 const keysFile = "keys.json"
@@ -18,13 +19,19 @@ const (
 	ActionHardDrop  Action = "hard_drop"
 	ActionRotateCW  Action = "rotate_cw"
 	ActionRotateCCW Action = "rotate_ccw"
+	ActionRotate180 Action = "rotate_180"
 	ActionHold      Action = "hold"
 	ActionPause     Action = "pause"
+	// The practice actions only work with practice on.
+	ActionUndo      Action = "undo"
+	ActionSaveState Action = "save_state"
+	ActionLoadState Action = "load_state"
 )
 
 var AllActions = []Action{
 	ActionMoveLeft, ActionMoveRight, ActionSoftDrop, ActionHardDrop,
-	ActionRotateCW, ActionRotateCCW, ActionHold, ActionPause,
+	ActionRotateCW, ActionRotateCCW, ActionRotate180, ActionHold, ActionPause,
+	ActionUndo, ActionSaveState, ActionLoadState,
 }
 
 // ActionLabel returns a human-readable label for an action.
@@ -42,10 +49,18 @@ func ActionLabel(a Action) string {
 		return "Rotate CW"
 	case ActionRotateCCW:
 		return "Rotate CCW"
+	case ActionRotate180:
+		return "Rotate 180"
 	case ActionHold:
 		return "Hold"
 	case ActionPause:
 		return "Pause"
+	case ActionUndo:
+		return "Undo Piece"
+	case ActionSaveState:
+		return "Save State"
+	case ActionLoadState:
+		return "Load State"
 	default:
 		return string(a)
 	}
@@ -54,6 +69,9 @@ func ActionLabel(a Action) string {
 // KeyBindings maps actions to their bound keys.
 type KeyBindings struct {
 	Bindings map[Action][]string `json:"bindings"`
+
+	// dir is where the bindings are saved; empty means Dir.
+	dir string
 }
 
 // DefaultKeyBindings returns the default key bindings (vim-style).
@@ -66,12 +84,73 @@ func DefaultKeyBindings() *KeyBindings {
 			ActionHardDrop:  {" ", "enter"},
 			ActionRotateCW:  {"k", "up", "x"},
 			ActionRotateCCW: {"z"},
+			ActionRotate180: {"a"},
 			ActionHold:      {"c"},
 			ActionPause:     {"p", "esc"},
+			ActionUndo:      {"u"},
+			ActionSaveState: {"s"},
+			ActionLoadState: {"r"},
 		},
 	}
 }
 
+// Key profiles are the bindings a player can pick in versus play, where
+// both players share one keyboard.
+const (
+	ProfileWASD   = "wasd"
+	ProfileArrows = "arrows"
+	// ProfileCustom uses the bindings from the key bindings screen.
+	ProfileCustom = "custom"
+)
+
+var KeyProfiles = []string{ProfileWASD, ProfileArrows, ProfileCustom}
+
+func isKeyProfile(name string) bool {
+	for _, p := range KeyProfiles {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ProfileKeyBindings returns the bindings of a key profile. custom is
+// returned for ProfileCustom.
+func ProfileKeyBindings(profile string, custom *KeyBindings) *KeyBindings {
+	switch profile {
+	case ProfileWASD:
+		return &KeyBindings{
+			Bindings: map[Action][]string{
+				ActionMoveLeft:  {"a"},
+				ActionMoveRight: {"d"},
+				ActionSoftDrop:  {"s"},
+				ActionHardDrop:  {" "},
+				ActionRotateCW:  {"w"},
+				ActionRotateCCW: {"q"},
+				ActionRotate180: {"r"},
+				ActionHold:      {"e"},
+				ActionPause:     {"esc"},
+			},
+		}
+	case ProfileArrows:
+		return &KeyBindings{
+			Bindings: map[Action][]string{
+				ActionMoveLeft:  {"left"},
+				ActionMoveRight: {"right"},
+				ActionSoftDrop:  {"down"},
+				ActionHardDrop:  {"enter"},
+				ActionRotateCW:  {"up"},
+				ActionRotateCCW: {"/"},
+				ActionRotate180: {","},
+				ActionHold:      {"."},
+				ActionPause:     {"esc"},
+			},
+		}
+	default:
+		return custom
+	}
+}
+
 // MatchAction finds the action for a given key string.
 func (kb *KeyBindings) MatchAction(key string) (Action, bool) {
 	for action, keys := range kb.Bindings {
@@ -94,37 +173,51 @@ func (kb *KeyBindings) GetKeys(action Action) []string {
 	return kb.Bindings[action]
 }
 
-func keysPath() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(home, configDir, keysFile), nil
-}
-
 // LoadKeyBindings reads key bindings from disk.
 func LoadKeyBindings() *KeyBindings {
-	path, err := keysPath()
+	return LoadKeyBindingsFrom("")
+}
+
+// LoadKeyBindingsFrom reads key bindings from a data directory other
+// than Dir. Save writes them back there.
+func LoadKeyBindingsFrom(dir string) *KeyBindings {
+	kb := DefaultKeyBindings()
+	kb.dir = dir
+
+	path, err := dataPath(dir, keysFile)
 	if err != nil {
-		return DefaultKeyBindings()
+		return kb
 	}
 
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return DefaultKeyBindings()
+		return kb
 	}
 
-	kb := DefaultKeyBindings()
-	if err := json.Unmarshal(data, kb); err != nil {
-		return DefaultKeyBindings()
+	saved := &KeyBindings{dir: dir}
+	if err := json.Unmarshal(data, saved); err != nil || saved.Bindings == nil {
+		return kb
 	}
 
-	return kb
+	// Actions added since the bindings were saved get their default keys,
+	// except any the saved bindings already use for another action.
+	for action, keys := range kb.Bindings {
+		if _, ok := saved.Bindings[action]; !ok {
+			saved.Bindings[action] = slices.DeleteFunc(keys, saved.bound)
+		}
+	}
+	return saved
+}
+
+// bound reports whether key is bound to any action.
+func (kb *KeyBindings) bound(key string) bool {
+	_, ok := kb.MatchAction(key)
+	return ok
 }
 
 // Save writes key bindings to disk.
 func (kb *KeyBindings) Save() error {
-	path, err := keysPath()
+	path, err := dataPath(kb.dir, keysFile)
 	if err != nil {
 		return err
 	}
@@ -1,16 +1,16 @@
 package game
 
-import "math/rand"
-
 // Bag implements the 7-bag randomizer.
 // Each bag contains one of each piece type, shuffled randomly.
 type Bag struct {
 	pieces []PieceType
+	rng    *rng
 }
 
-// NewBag creates a new 7-bag randomizer.
-func NewBag() *Bag {
-	b := &Bag{}
+// NewBag creates a new 7-bag randomizer. The same seed always produces
+// the same piece sequence.
+func NewBag(seed uint64) *Bag {
+	b := &Bag{rng: newRNG(seed)}
 	b.refill()
 	return b
 }
@@ -39,7 +39,7 @@ func (b *Bag) Preview(n int) []PieceType {
 func (b *Bag) refill() {
 	newBag := make([]PieceType, len(AllPieceTypes))
 	copy(newBag, AllPieceTypes)
-	rand.Shuffle(len(newBag), func(i, j int) {
+	b.rng.shuffle(len(newBag), func(i, j int) {
 		newBag[i], newBag[j] = newBag[j], newBag[i]
 	})
 	b.pieces = append(b.pieces, newBag...)
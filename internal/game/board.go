@@ -1,25 +1,116 @@
 package game
 
+import "slices"
+
+// Board sizes. Width and height count the visible board; BufferRows more
+// rows above it hold pieces as they spawn, whatever the size.
 const (
-	BoardWidth  = 10
-	BoardHeight = 24 // 20 visible + 4 buffer rows at top
-	VisibleRows = 20
-	BufferRows  = 4
+	DefaultWidth  = 10
+	DefaultHeight = 20
+	MinWidth      = 4
+	MaxWidth      = 40
+	MinHeight     = 10
+	MaxHeight     = 40
+	BufferRows    = 4
 )
 
 // Board represents the Tetris playing field.
 type Board struct {
-	Cells [BoardHeight][BoardWidth]CellColor
+	// Width is the number of columns and Height the number of visible
+	// rows.
+	Width, Height int
+	// Cells holds the rows top first: BufferRows hidden ones, then the
+	// visible ones.
+	Cells [][]CellColor
+	// System is the rotation system of the pieces on the board.
+	System RotationSystem
+}
+
+// NewBoard creates an empty board of the given visible size, for pieces
+// that turn by SRS.
+func NewBoard(width, height int) *Board {
+	b := &Board{Width: width, Height: height, Cells: make([][]CellColor, height+BufferRows), System: SRS}
+	cells := make([]CellColor, width*len(b.Cells))
+	for r := range b.Cells {
+		b.Cells[r] = cells[r*width : (r+1)*width : (r+1)*width]
+	}
+	return b
+}
+
+// BoardPreset is a named board size.
+type BoardPreset struct {
+	Name          string
+	Width, Height int
+}
+
+// BoardPresets are the board sizes offered by name, standard first.
+var BoardPresets = []BoardPreset{
+	{"standard", DefaultWidth, DefaultHeight},
+	{"big", 20, 30},
+	{"narrow", 4, DefaultHeight},
+}
+
+// ClampBoardSize returns the nearest supported board size to width x
+// height; zero stands for the standard size.
+func ClampBoardSize(width, height int) (int, int) {
+	if width == 0 {
+		width = DefaultWidth
+	}
+	if height == 0 {
+		height = DefaultHeight
+	}
+	return min(max(width, MinWidth), MaxWidth), min(max(height, MinHeight), MaxHeight)
+}
+
+// Rows returns the number of rows, hidden ones included.
+func (b *Board) Rows() int {
+	return len(b.Cells)
+}
+
+// Standard reports whether the board has the standard size.
+func (b *Board) Standard() bool {
+	return b.Width == DefaultWidth && b.Height == DefaultHeight
 }
 
-// NewBoard creates an empty board.
-func NewBoard() *Board {
-	return &Board{}
+// Clone returns a copy of the board that shares no cells with it.
+func (b *Board) Clone() *Board {
+	c := b.empty()
+	for r, row := range b.Cells {
+		copy(c.Cells[r], row)
+	}
+	return c
+}
+
+// empty returns an empty board of b's size and rotation system.
+func (b *Board) empty() *Board {
+	e := NewBoard(b.Width, b.Height)
+	e.System = b.System
+	return e
+}
+
+// Equal reports whether two boards have the same size and cells.
+func (b *Board) Equal(o *Board) bool {
+	if b.Width != o.Width || b.Height != o.Height {
+		return false
+	}
+	for r, row := range b.Cells {
+		if !slices.Equal(row, o.Cells[r]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Clear empties the board.
+func (b *Board) Clear() {
+	for _, row := range b.Cells {
+		clear(row)
+	}
 }
 
 // InBounds checks if a position is within the board.
 func (b *Board) InBounds(pos Position) bool {
-	return pos.Row >= 0 && pos.Row < BoardHeight && pos.Col >= 0 && pos.Col < BoardWidth
+	return pos.Row >= 0 && pos.Row < len(b.Cells) && pos.Col >= 0 && pos.Col < b.Width
 }
 
 // IsEmpty checks if a cell is empty (and in bounds).
@@ -35,9 +126,24 @@ func (b *Board) IsOccupied(pos Position) bool {
 	return !b.IsEmpty(pos)
 }
 
+// IsClear reports whether the board has no occupied cells.
+func (b *Board) IsClear() bool {
+	for row := range b.Cells {
+		for _, c := range b.Cells[row] {
+			if c != Empty {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // ValidPosition checks if a piece can exist at its current position.
 func (b *Board) ValidPosition(p *Piece) bool {
-	for _, cell := range p.Cells() {
+	// Walks the offsets directly rather than via Cells: this is the
+	// hottest call in the engine and the AI search.
+	for _, off := range b.System.Shape(p.Type, p.Rotation) {
+		cell := Position{Row: p.Pos.Row + off.Row, Col: p.Pos.Col + off.Col}
 		if !b.InBounds(cell) || b.Cells[cell.Row][cell.Col] != Empty {
 			return false
 		}
@@ -45,10 +151,36 @@ func (b *Board) ValidPosition(p *Piece) bool {
 	return true
 }
 
+// PieceCells returns the cells p covers on the board.
+func (b *Board) PieceCells(p *Piece) []Position {
+	offsets := b.System.Shape(p.Type, p.Rotation)
+	cells := make([]Position, len(offsets))
+	for i, off := range offsets {
+		cells[i] = Position{Row: p.Pos.Row + off.Row, Col: p.Pos.Col + off.Col}
+	}
+	return cells
+}
+
+// Rotate turns p to rotation to, trying each wall kick of the rotation
+// system in order. It returns the rotated piece and the index of the kick
+// that fit.
+func (b *Board) Rotate(p *Piece, to Rotation) (Piece, int, bool) {
+	for i, kick := range b.System.Kicks(b, p, to) {
+		test := p.Clone()
+		test.Rotation = to
+		test.Pos.Col += kick.Col
+		test.Pos.Row += kick.Row
+		if b.ValidPosition(&test) {
+			return test, i, true
+		}
+	}
+	return Piece{}, 0, false
+}
+
 // PlacePiece locks a piece onto the board.
 func (b *Board) PlacePiece(p *Piece) {
 	color := PieceColor(p.Type)
-	for _, cell := range p.Cells() {
+	for _, cell := range b.PieceCells(p) {
 		if b.InBounds(cell) {
 			b.Cells[cell.Row][cell.Col] = color
 		}
@@ -59,15 +191,8 @@ func (b *Board) PlacePiece(p *Piece) {
 // and the row indices that were cleared.
 func (b *Board) ClearLines() (int, []int) {
 	var cleared []int
-	for row := BoardHeight - 1; row >= 0; row-- {
-		full := true
-		for col := 0; col < BoardWidth; col++ {
-			if b.Cells[row][col] == Empty {
-				full = false
-				break
-			}
-		}
-		if full {
+	for row := len(b.Cells) - 1; row >= 0; row-- {
+		if !slices.Contains(b.Cells[row], Empty) {
 			cleared = append(cleared, row)
 		}
 	}
@@ -76,27 +201,63 @@ func (b *Board) ClearLines() (int, []int) {
 		return 0, nil
 	}
 
-	// Remove cleared rows and shift everything down.
-	newCells := [BoardHeight][BoardWidth]CellColor{}
-	writeRow := BoardHeight - 1
-	for readRow := BoardHeight - 1; readRow >= 0; readRow-- {
-		isClear := false
-		for _, cr := range cleared {
-			if readRow == cr {
-				isClear = true
-				break
-			}
-		}
-		if !isClear {
-			newCells[writeRow] = b.Cells[readRow]
-			writeRow--
+	// Remove cleared rows and shift everything down. The cleared rows
+	// are emptied and reused at the top.
+	rows := make([][]CellColor, 0, len(b.Cells))
+	for _, row := range cleared {
+		clear(b.Cells[row])
+		rows = append(rows, b.Cells[row])
+	}
+	for row := range b.Cells {
+		if !slices.Contains(cleared, row) {
+			rows = append(rows, b.Cells[row])
 		}
 	}
-	b.Cells = newCells
+	b.Cells = rows
 
 	return len(cleared), cleared
 }
 
+// AddGarbage pushes rows of garbage up from the bottom, one per entry of
+// holes, each with a single empty cell at the given column. It reports
+// false if an occupied cell was pushed off the top of the board.
+func (b *Board) AddGarbage(holes []int) bool {
+	n := min(len(holes), len(b.Cells))
+	ok := true
+	for row := 0; row < n; row++ {
+		if slices.ContainsFunc(b.Cells[row], func(c CellColor) bool { return c != Empty }) {
+			ok = false
+		}
+	}
+
+	// The rows pushed off the top come back as the garbage at the bottom.
+	top := slices.Clone(b.Cells[:n])
+	copy(b.Cells, b.Cells[n:])
+	copy(b.Cells[len(b.Cells)-n:], top)
+	for i, hole := range holes[len(holes)-n:] {
+		row := b.Cells[len(b.Cells)-n+i]
+		for col := range row {
+			row[col] = ColorGarbage
+		}
+		row[hole] = Empty
+	}
+	return ok
+}
+
+// GarbageRows returns how many rows contain garbage.
+func (b *Board) GarbageRows() int {
+	n := 0
+	for row := range b.Cells {
+		for _, c := range b.Cells[row] {
+			if c == ColorGarbage {
+				n++
+				break
+			}
+		}
+	}
+	return n
+}
+
 // GhostPosition returns the position a piece would be at if hard dropped.
 func (b *Board) GhostPosition(p *Piece) Position {
 	ghost := p.Clone()
@@ -113,8 +274,8 @@ func (b *Board) GhostPosition(p *Piece) Position {
 // IsAboveVisible checks if any occupied cell is in the buffer zone (above visible area).
 func (b *Board) IsAboveVisible() bool {
 	for row := 0; row < BufferRows; row++ {
-		for col := 0; col < BoardWidth; col++ {
-			if b.Cells[row][col] != Empty {
+		for _, c := range b.Cells[row] {
+			if c != Empty {
 				return true
 			}
 		}
@@ -0,0 +1,103 @@
+package game
+
+import "time"
+
+// DisplayState is everything a playfield shows of a game: enough to draw
+// it elsewhere, as spectators do, but not to continue playing it.
+type DisplayState struct {
+	Mode  Mode      `json:"mode"`
+	State GameState `json:"state"`
+	// Width and Height are the visible board size; zero means standard.
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	// Rotation names the rotation system, which decides the shapes of
+	// the pieces; empty means SRS.
+	Rotation string `json:"rotation,omitempty"`
+	// Board holds the board rows, top (buffer) row first.
+	Board     [][]CellColor `json:"board,omitempty"`
+	Current   *Piece        `json:"current,omitempty"`
+	HoldPiece *PieceType    `json:"hold,omitempty"`
+	HoldUsed  bool          `json:"hold_used,omitempty"`
+	Next      []PieceType   `json:"next"`
+	Scorer    Scorer        `json:"scorer"`
+	Clock     time.Duration `json:"clock"`
+
+	LineGoal       int           `json:"line_goal,omitempty"`
+	TimeLimit      time.Duration `json:"time_limit,omitempty"`
+	GarbageGoal    int           `json:"garbage_goal,omitempty"`
+	GarbageCleared int           `json:"garbage_cleared,omitempty"`
+	PendingGarbage int           `json:"pending_garbage,omitempty"`
+	PiecesPlaced   int           `json:"pieces"`
+	LastClear      ClearInfo     `json:"last_clear"`
+}
+
+// Display captures what the engine currently shows. The result shares
+// nothing with the engine.
+func (e *Engine) Display() DisplayState {
+	s := DisplayState{
+		Mode:           e.Mode,
+		State:          e.State,
+		Width:          e.Board.Width,
+		Height:         e.Board.Height,
+		Rotation:       e.Board.System.Name(),
+		Board:          make([][]CellColor, e.Board.Rows()),
+		HoldUsed:       e.HoldUsed,
+		Next:           e.NextPieces(),
+		Scorer:         *e.Scorer,
+		Clock:          e.Clock,
+		LineGoal:       e.LineGoal,
+		TimeLimit:      e.TimeLimit,
+		GarbageGoal:    e.GarbageGoal,
+		GarbageCleared: e.GarbageCleared(),
+		PendingGarbage: e.PendingGarbage(),
+		PiecesPlaced:   e.PiecesPlaced,
+		LastClear:      e.LastClear,
+	}
+	for r := range s.Board {
+		s.Board[r] = append([]CellColor(nil), e.Board.Cells[r]...)
+	}
+	if e.Current != nil {
+		p := e.Current.Clone()
+		s.Current = &p
+	}
+	if e.HoldPiece != nil {
+		h := *e.HoldPiece
+		s.HoldPiece = &h
+	}
+	return s
+}
+
+// NewDisplayEngine creates an engine that shows s, for drawing with the
+// same code as a game in play. It must not be played: its bag holds only
+// the preview queue.
+func NewDisplayEngine(s DisplayState) *Engine {
+	e := &Engine{
+		Board:        NewBoard(ClampBoardSize(s.Width, s.Height)),
+		Bag:          &Bag{pieces: append([]PieceType(nil), s.Next...)},
+		Scorer:       &Scorer{},
+		State:        s.State,
+		Current:      s.Current,
+		HoldPiece:    s.HoldPiece,
+		HoldUsed:     s.HoldUsed,
+		PreviewCount: len(s.Next),
+		Mode:         s.Mode,
+		LineGoal:     s.LineGoal,
+		TimeLimit:    s.TimeLimit,
+		Clock:        s.Clock,
+		PiecesPlaced: s.PiecesPlaced,
+		GarbageGoal:  s.GarbageGoal,
+		LastClear:    s.LastClear,
+	}
+	*e.Scorer = s.Scorer
+	if rs, ok := RotationSystemNamed(s.Rotation); ok {
+		e.Board.System = rs
+	}
+	for r := 0; r < e.Board.Rows() && r < len(s.Board); r++ {
+		copy(e.Board.Cells[r], s.Board[r])
+	}
+	if s.PendingGarbage > 0 {
+		e.Garbage = []int{s.PendingGarbage}
+	}
+	e.garbageAdded = s.GarbageCleared + e.Board.GarbageRows()
+	return e
+}
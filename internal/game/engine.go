@@ -17,30 +17,77 @@ type Engine struct {
 	HoldPiece    *PieceType
 	HoldUsed     bool
 	PreviewCount int
-
-	// Lock delay tracking.
-	LockTimer   time.Time
+	Seed         uint64
+	Mode         Mode
+	LineGoal     int
+	TimeLimit    time.Duration
+
+	// Clock is the game time. It only moves forward through Advance, so
+	// time spent paused never counts.
+	Clock time.Duration
+
+	// Lock delay tracking. LockTimer is the Clock value when the lock
+	// delay (re)started.
+	LockTimer   time.Duration
 	LockResets  int
 	LockStarted bool
 
-	// T-Spin detection.
+	// nextGravity is the Clock value at which the next gravity step is due.
+	nextGravity time.Duration
+
+	startLevel int
+
+	// T-Spin detection. lastKick is the SpinKick of the last successful
+	// rotation.
 	LastMoveWasRotation bool
+	lastKick            int
 
 	// Stats.
 	PiecesPlaced int
-	StartTime    time.Time
+	// Garbage holds the queued incoming attacks, oldest first, and
+	// Outgoing the lines sent and not yet taken.
+	Garbage          []int
+	Outgoing         int
+	GarbageMessiness float64
+	GarbageGoal      int
+	garbageRNG       *rng
+	garbageHole      int
+	garbageAdded     int
+
+	// LastClear is the most recent line clear or T-Spin; its Type is
+	// ClearNone before the first one.
+	LastClear ClearInfo
+
+	// subscribers get the engine's events; see Subscribe.
+	subscribers    []subscriber
+	nextSubscriber int
 }
 
-// NewEngine creates a new game engine.
-func NewEngine(startLevel, previewCount int) *Engine {
+// NewEngine creates a new game engine. The piece sequence is determined
+// by opts.Seed.
+func NewEngine(opts Options) *Engine {
 	e := &Engine{
-		Board:        NewBoard(),
-		Bag:          NewBag(),
-		Scorer:       NewScorer(startLevel),
+		Board:        NewBoard(ClampBoardSize(opts.Width, opts.Height)),
+		Bag:          NewBag(opts.Seed),
+		Scorer:       NewScorer(opts.StartLevel),
 		State:        StatePlaying,
-		PreviewCount: previewCount,
-		StartTime:    time.Now(),
-	}
+		PreviewCount: opts.PreviewCount,
+		Seed:         opts.Seed,
+		Mode:         opts.Mode,
+		LineGoal:     opts.LineGoal,
+		TimeLimit:    opts.TimeLimit,
+		startLevel:   opts.StartLevel,
+
+		GarbageMessiness: opts.GarbageMessiness,
+		GarbageGoal:      opts.GarbageGoal,
+		garbageRNG:       newRNG(opts.Seed ^ garbageSalt),
+		garbageHole:      -1,
+	}
+	if opts.Rotation != nil {
+		e.Board.System = opts.Rotation
+	}
+	e.topUpGarbage()
+	e.nextGravity = e.gravityInterval()
 	e.spawnPiece()
 	return e
 }
@@ -52,11 +99,11 @@ func (e *Engine) spawnPiece() bool {
 	p := &Piece{
 		Type:     pt,
 		Rotation: Rot0,
-		Pos:      SpawnPosition(pt),
+		Pos:      e.Board.SpawnPosition(pt),
 	}
 
 	if !e.Board.ValidPosition(p) {
-		e.State = StateGameOver
+		e.topOut()
 		return false
 	}
 
@@ -65,9 +112,43 @@ func (e *Engine) spawnPiece() bool {
 	e.LockStarted = false
 	e.LockResets = 0
 	e.LastMoveWasRotation = false
+	e.emit(PieceSpawned{Piece: *p})
 	return true
 }
 
+// TimeLeft returns the time remaining before the time limit, or zero if
+// there is none.
+func (e *Engine) TimeLeft() time.Duration {
+	if e.TimeLimit == 0 {
+		return 0
+	}
+	return max(e.TimeLimit-e.Clock, 0)
+}
+
+// Over reports whether the game has ended, by topping out or by reaching
+// the mode's goal.
+func (e *Engine) Over() bool {
+	return e.State == StateGameOver || e.State == StateFinished
+}
+
+// Options returns the options the engine was created with.
+func (e *Engine) Options() Options {
+	return Options{
+		Mode:         e.Mode,
+		StartLevel:   e.startLevel,
+		PreviewCount: e.PreviewCount,
+		Seed:         e.Seed,
+		LineGoal:     e.LineGoal,
+		TimeLimit:    e.TimeLimit,
+
+		GarbageMessiness: e.GarbageMessiness,
+		GarbageGoal:      e.GarbageGoal,
+		Width:            e.Board.Width,
+		Height:           e.Board.Height,
+		Rotation:         e.Board.System,
+	}
+}
+
 // NextPieces returns the upcoming pieces for preview.
 func (e *Engine) NextPieces() []PieceType {
 	return e.Bag.Preview(e.PreviewCount)
@@ -153,42 +234,53 @@ func (e *Engine) HardDrop() LineClearType {
 		e.Current.Pos.Row++
 		dropDist++
 	}
+	if dropDist > 0 {
+		e.LastMoveWasRotation = false
+	}
 	e.Scorer.AddHardDrop(dropDist)
 	return e.lockPiece()
 }
 
-// RotateCW rotates the piece clockwise using SRS wall kicks.
+// RotateCW rotates the piece clockwise, kicking it as the board's
+// rotation system allows.
 func (e *Engine) RotateCW() bool {
 	if e.State != StatePlaying || e.Current == nil {
 		return false
 	}
-	return e.rotate(nextRotCW(e.Current.Rotation))
+	return e.rotate(e.Current.Rotation.CW())
 }
 
-// RotateCCW rotates the piece counter-clockwise using SRS wall kicks.
+// RotateCCW rotates the piece counter-clockwise, kicking it as the
+// board's rotation system allows.
 func (e *Engine) RotateCCW() bool {
 	if e.State != StatePlaying || e.Current == nil {
 		return false
 	}
-	return e.rotate(nextRotCCW(e.Current.Rotation))
+	return e.rotate(e.Current.Rotation.CCW())
+}
+
+// Rotate180 turns the piece halfway round, kicking it as the board's
+// rotation system allows. It counts as a rotation for T-Spins.
+func (e *Engine) Rotate180() bool {
+	if e.State != StatePlaying || e.Current == nil {
+		return false
+	}
+	return e.rotate(e.Current.Rotation.Half())
 }
 
 func (e *Engine) rotate(newRot Rotation) bool {
-	kicks := GetWallKicks(e.Current.Type, e.Current.Rotation, newRot)
-	for _, kick := range kicks {
-		test := e.Current.Clone()
-		test.Rotation = newRot
-		test.Pos.Col += kick.Col
-		test.Pos.Row -= kick.Row // SRS: positive row = up
-		if e.Board.ValidPosition(&test) {
-			e.Current.Rotation = test.Rotation
-			e.Current.Pos = test.Pos
-			e.LastMoveWasRotation = true
-			e.resetLockIfNeeded()
-			return true
-		}
+	half := newRot == e.Current.Rotation.Half()
+	rotated, kick, ok := e.Board.Rotate(e.Current, newRot)
+	if !ok {
+		return false
 	}
-	return false
+	e.Current.Rotation = rotated.Rotation
+	e.Current.Pos = rotated.Pos
+	e.LastMoveWasRotation = true
+	e.lastKick = SpinKick(kick, half)
+	e.resetLockIfNeeded()
+	e.emit(PieceRotated{Piece: *e.Current, Kick: kick})
+	return true
 }
 
 // Hold swaps the current piece with the hold piece.
@@ -197,6 +289,7 @@ func (e *Engine) Hold() bool {
 		return false
 	}
 	currentType := e.Current.Type
+	e.emit(Hold{Held: currentType})
 	if e.HoldPiece != nil {
 		// Swap with held piece.
 		heldType := *e.HoldPiece
@@ -204,13 +297,14 @@ func (e *Engine) Hold() bool {
 		p := &Piece{
 			Type:     heldType,
 			Rotation: Rot0,
-			Pos:      SpawnPosition(heldType),
+			Pos:      e.Board.SpawnPosition(heldType),
 		}
 		if !e.Board.ValidPosition(p) {
-			e.State = StateGameOver
+			e.topOut()
 			return false
 		}
 		e.Current = p
+		e.emit(PieceSpawned{Piece: *p})
 	} else {
 		e.HoldPiece = &currentType
 		e.spawnPiece()
@@ -222,6 +316,49 @@ func (e *Engine) Hold() bool {
 	return true
 }
 
+// Advance moves the game clock forward by dt, applying gravity steps and
+// lock delay expiry at the exact moments they fall due. Advancing by the
+// same total in different increments always produces the same game.
+// It returns the last line clear that happened, if any.
+func (e *Engine) Advance(dt time.Duration) LineClearType {
+	result := ClearNone
+	target := e.Clock + dt
+	for e.State == StatePlaying {
+		next, isLock := e.nextGravity, false
+		if e.LockStarted && e.LockTimer+LockDelay < next {
+			next, isLock = e.LockTimer+LockDelay, true
+		}
+		if e.TimeLimit > 0 && e.TimeLimit <= next && e.TimeLimit <= target {
+			e.Clock = e.TimeLimit
+			e.finish()
+			break
+		}
+		if next > target {
+			break
+		}
+		e.Clock = next
+
+		var clearType LineClearType
+		if isLock {
+			clearType = e.CheckLock()
+		} else {
+			e.nextGravity += e.gravityInterval()
+			clearType = e.Tick()
+		}
+		if clearType != ClearNone {
+			result = clearType
+		}
+	}
+	if e.State == StatePlaying {
+		e.Clock = target
+	}
+	return result
+}
+
+func (e *Engine) gravityInterval() time.Duration {
+	return time.Duration(float64(time.Second) * e.Scorer.GravityInterval())
+}
+
 // Tick advances the game by one gravity step.
 func (e *Engine) Tick() LineClearType {
 	if e.State != StatePlaying || e.Current == nil {
@@ -236,12 +373,12 @@ func (e *Engine) Tick() LineClearType {
 	// Piece can't move down - start or continue lock delay
 	if !e.LockStarted {
 		e.LockStarted = true
-		e.LockTimer = time.Now()
+		e.LockTimer = e.Clock
 		return ClearNone
 	}
 
 	// Check if lock delay expired
-	if time.Since(e.LockTimer) >= LockDelay {
+	if e.Clock-e.LockTimer >= LockDelay {
 		return e.lockPiece()
 	}
 
@@ -262,7 +399,7 @@ func (e *Engine) CheckLock() LineClearType {
 	}
 
 	// Check if lock delay expired
-	if time.Since(e.LockTimer) >= LockDelay {
+	if e.Clock-e.LockTimer >= LockDelay {
 		return e.lockPiece()
 	}
 
@@ -284,7 +421,7 @@ func (e *Engine) GhostCells() []Position {
 	}
 	ghost := e.Current.Clone()
 	ghost.Pos = e.GhostPosition()
-	return ghost.Cells()
+	return e.Board.PieceCells(&ghost)
 }
 
 func (e *Engine) lockPiece() LineClearType {
@@ -293,15 +430,68 @@ func (e *Engine) lockPiece() LineClearType {
 	}
 
 	// Detect T-spin before placing.
-	isTSpin := e.detectTSpin()
+	tspin := e.detectTSpin()
+	e.emit(PieceLocked{Piece: *e.Current, TSpin: tspin})
 
 	// Place the piece on the board
 	e.Board.PlacePiece(e.Current)
 	e.PiecesPlaced++
 
 	// Clear lines
-	linesCleared, _ := e.Board.ClearLines()
-	clearType := e.Scorer.AddLineClear(linesCleared, isTSpin)
+	linesCleared, rows := e.Board.ClearLines()
+	perfectClear := linesCleared > 0 && e.Board.IsClear()
+	backToBack := e.Scorer.BackToBack
+	level := e.Scorer.Level
+	clearType := e.Scorer.AddLineClear(linesCleared, tspin, perfectClear)
+	if clearType != ClearNone {
+		e.LastClear = ClearInfo{
+			Type:         clearType,
+			Lines:        linesCleared,
+			PerfectClear: perfectClear,
+			BackToBack:   backToBack && IsDifficultClear(clearType),
+			Time:         e.Clock,
+		}
+	}
+	if linesCleared > 0 {
+		e.emit(LinesCleared{
+			Rows:         rows,
+			Type:         clearType,
+			PerfectClear: perfectClear,
+			BackToBack:   e.LastClear.BackToBack,
+			Combo:        e.Scorer.Combo - 1,
+		})
+	}
+	if tspin != TSpinNone {
+		e.emit(TSpin{Kind: tspin, Lines: linesCleared, Type: clearType})
+	}
+	if e.Scorer.Level > level {
+		e.emit(LevelUp{Level: e.Scorer.Level})
+	}
+
+	// The finesse trainer plays every piece on an empty board.
+	if e.Mode == ModeFinesse {
+		e.Board.Clear()
+	}
+
+	if (e.LineGoal > 0 && e.Scorer.Lines >= e.LineGoal) ||
+		(e.GarbageGoal > 0 && e.GarbageCleared() >= e.GarbageGoal) {
+		e.finish()
+		return clearType
+	}
+
+	// Clears cancel incoming garbage first and send the rest; a lock that
+	// clears nothing lets the queued garbage in.
+	ok := true
+	if linesCleared > 0 {
+		e.Outgoing += e.cancelGarbage(Attack(e.LastClear, e.Scorer.Combo-1))
+	} else {
+		ok = e.insertGarbage()
+	}
+	if !ok || !e.topUpGarbage() {
+		e.Current = nil
+		e.topOut()
+		return clearType
+	}
 
 	// Spawn next piece
 	if !e.spawnPiece() {
@@ -311,49 +501,77 @@ func (e *Engine) lockPiece() LineClearType {
 	return clearType
 }
 
-func (e *Engine) detectTSpin() bool {
-	if e.Current.Type != PieceT || !e.LastMoveWasRotation {
-		return false
+// TSpin classifies a T piece that has just been rotated into place on
+// the board, with kick as returned by SpinKick.
+func (b *Board) TSpin(p *Piece, kick int) TSpinKind {
+	if p.Type != PieceT {
+		return TSpinNone
+	}
+
+	// The corners around the T's middle cell: the front two on the side
+	// it points to, the back two behind it.
+	center, point := tPoint(b.System.Shape(PieceT, p.Rotation))
+	center.Row += p.Pos.Row
+	center.Col += p.Pos.Col
+	side := Position{Row: point.Col, Col: point.Row}
+	filled := func(ahead int) int {
+		n := 0
+		for _, s := range [...]int{-1, 1} {
+			pos := Position{
+				Row: center.Row + ahead*point.Row + s*side.Row,
+				Col: center.Col + ahead*point.Col + s*side.Col,
+			}
+			if b.IsOccupied(pos) {
+				n++
+			}
+		}
+		return n
 	}
+	front := filled(1)
+	back := filled(-1)
 
-	// Check 3 of 4 corners of the T's bounding box are occupied.
-	row := e.Current.Pos.Row
-	col := e.Current.Pos.Col
-	corners := []Position{
-		{row, col},
-		{row, col + 2},
-		{row + 2, col},
-		{row + 2, col + 2},
+	switch {
+	case front+back < 3:
+		return TSpinNone
+	case front == 2 || kick == 4:
+		return TSpinFull
+	default:
+		return TSpinMini
 	}
+}
 
-	occupied := 0
-	for _, c := range corners {
-		if !e.Board.InBounds(c) || e.Board.Cells[c.Row][c.Col] != Empty {
-			occupied++
-		}
+// SpinKick returns the kick a rotation counts as for TSpin: the index of
+// the kick test for a quarter turn, and 0 for a half turn, whose kicks
+// never make a Mini a full T-Spin.
+func SpinKick(kick int, half bool) int {
+	if half {
+		return 0
 	}
+	return kick
+}
 
-	return occupied >= 3
+// detectTSpin classifies the current piece using the guideline corner
+// rules: both front corners and a back corner make a T-Spin, both back
+// corners and a front corner make a Mini, unless a quarter turn used the
+// last kick test (the TST kick), which always counts as a full T-Spin.
+// Half turns count as rotations too.
+func (e *Engine) detectTSpin() TSpinKind {
+	if !e.LastMoveWasRotation {
+		return TSpinNone
+	}
+	return e.Board.TSpin(e.Current, e.lastKick)
 }
 
 func (e *Engine) resetLockIfNeeded() {
 	if e.LockStarted && e.LockResets < MaxLockResets {
-		e.LockTimer = time.Now()
+		e.LockTimer = e.Clock
 		e.LockResets++
 	}
 }
 
-func nextRotCW(r Rotation) Rotation {
-	return (r + 1) % 4
-}
-
-func nextRotCCW(r Rotation) Rotation {
-	return (r + 3) % 4
-}
-
-// ElapsedTime returns the game duration.
+// ElapsedTime returns the game duration, excluding time spent paused.
 func (e *Engine) ElapsedTime() time.Duration {
-	return time.Since(e.StartTime)
+	return e.Clock
 }
 
 // PiecesPerSecond returns the placement rate.
@@ -0,0 +1,127 @@
+package game
+
+// Event is something that happened in a game. Subscribers tell the kinds
+// apart with a type switch; the engine's Clock is the time it happened.
+type Event interface {
+	event()
+}
+
+// PieceSpawned is sent when a new current piece enters the board, from
+// the queue or from hold.
+type PieceSpawned struct {
+	Piece Piece
+}
+
+// PieceRotated is sent for every successful rotation. Kick is the index
+// of the wall kick test that fit; anything but 0 moved the piece.
+type PieceRotated struct {
+	Piece Piece
+	Kick  int
+}
+
+// Hold is sent when the current piece goes into hold. Held is its type.
+// A PieceSpawned for the piece that replaces it follows.
+type Hold struct {
+	Held PieceType
+}
+
+// PieceLocked is sent when a piece locks, just before it is placed on the
+// board, so the board is still as the piece found it.
+type PieceLocked struct {
+	Piece Piece
+	TSpin TSpinKind
+}
+
+// LinesCleared is sent when a lock completes lines. Rows are their
+// indices on the board before they were removed, bottom first.
+type LinesCleared struct {
+	Rows         []int
+	Type         LineClearType
+	PerfectClear bool
+	BackToBack   bool
+	// Combo counts the consecutive clearing locks before this one.
+	Combo int
+}
+
+// TSpin is sent when a lock is a T-Spin, with or without lines. Type is
+// the clear it scored as.
+type TSpin struct {
+	Kind  TSpinKind
+	Lines int
+	Type  LineClearType
+}
+
+// LevelUp is sent when the level goes up.
+type LevelUp struct {
+	Level int
+}
+
+// GarbageReceived is sent when garbage rises onto the board, with the
+// hole column of each row, bottom row last.
+type GarbageReceived struct {
+	Lines int
+	Holes []int
+}
+
+// TopOut is sent when the game is lost.
+type TopOut struct{}
+
+// Finished is sent when the game ends by reaching the mode's goal or time
+// limit.
+type Finished struct{}
+
+func (PieceSpawned) event()    {}
+func (PieceRotated) event()    {}
+func (Hold) event()            {}
+func (PieceLocked) event()     {}
+func (LinesCleared) event()    {}
+func (TSpin) event()           {}
+func (LevelUp) event()         {}
+func (GarbageReceived) event() {}
+func (TopOut) event()          {}
+func (Finished) event()        {}
+
+// subscriber is a function registered with Subscribe.
+type subscriber struct {
+	id int
+	fn func(Event)
+}
+
+// Subscribe calls fn with every event of the engine from now on. Events
+// are delivered synchronously, in order, from inside the engine method
+// that caused them, so fn sees the engine as the event left it and must
+// not call back into the engine's actions. The returned function
+// unsubscribes fn.
+func (e *Engine) Subscribe(fn func(Event)) (unsubscribe func()) {
+	e.nextSubscriber++
+	id := e.nextSubscriber
+	e.subscribers = append(e.subscribers, subscriber{id: id, fn: fn})
+	return func() {
+		for i, s := range e.subscribers {
+			if s.id == id {
+				e.subscribers = append(e.subscribers[:i:i], e.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// emit sends an event to the subscribers.
+func (e *Engine) emit(ev Event) {
+	for _, s := range e.subscribers {
+		s.fn(ev)
+	}
+}
+
+// topOut ends the game as lost.
+func (e *Engine) topOut() {
+	e.State = StateGameOver
+	e.emit(TopOut{})
+}
+
+// finish ends the game as finished.
+func (e *Engine) finish() {
+	e.State = StateFinished
+	e.Current = nil
+	e.emit(Finished{})
+}
@@ -0,0 +1,216 @@
+package game
+
+import (
+	"slices"
+	"sync"
+)
+
+// Finesse is placing a piece with as few key presses as possible. The
+// presses counted are moves, where holding a direction until the piece
+// reaches the wall (DAS) counts as one, and rotations, including 180°
+// turns for players who have a key for them; soft drops, hard drops and
+// holds are free.
+
+// finesseInput is one key press of the finesse search.
+type finesseInput int
+
+const (
+	inputLeft finesseInput = iota
+	inputRight
+	inputDASLeft
+	inputDASRight
+	inputCW
+	inputCCW
+	input180
+)
+
+var finesseInputs = []finesseInput{inputLeft, inputRight, inputDASLeft, inputDASRight, inputCW, inputCCW}
+
+// finesseInputs180 adds 180° turns to finesseInputs.
+var finesseInputs180 = append(slices.Clip(finesseInputs), input180)
+
+// footprint is the cells a piece covers once dropped on an empty board,
+// in a fixed order, so rotations covering the same cells are one
+// destination.
+type footprint [4]Position
+
+// finesseTable holds the fewest presses from spawn to every destination,
+// per piece type.
+type finesseTable [PieceL + 1]map[footprint]int
+
+// finesseKey identifies the boards that share a finesse table.
+type finesseKey struct {
+	width   int
+	system  string
+	turn180 bool
+}
+
+// finesseTables caches the table of each board width, rotation system
+// and set of inputs, built the first time such a board is judged.
+var finesseTables struct {
+	sync.Mutex
+	byKey map[finesseKey]*finesseTable
+}
+
+// finesseTableOf returns the finesse table of boards like b, with or
+// without 180° turns.
+func finesseTableOf(b *Board, turn180 bool) *finesseTable {
+	key := finesseKey{b.Width, b.System.Name(), turn180}
+	finesseTables.Lock()
+	defer finesseTables.Unlock()
+	if t, ok := finesseTables.byKey[key]; ok {
+		return t
+	}
+	inputs := finesseInputs
+	if turn180 {
+		inputs = finesseInputs180
+	}
+	t := &finesseTable{}
+	for _, pt := range AllPieceTypes {
+		t[pt] = finesseSearch(pt, b.flat(), inputs)
+	}
+	if finesseTables.byKey == nil {
+		finesseTables.byKey = make(map[finesseKey]*finesseTable)
+	}
+	finesseTables.byKey[key] = t
+	return t
+}
+
+// flat returns an empty board of b's width and rotation system, with
+// the standard height: finesse doesn't depend on how tall a board is.
+func (b *Board) flat() *Board {
+	f := NewBoard(b.Width, DefaultHeight)
+	f.System = b.System
+	return f
+}
+
+// finesseSearch finds the fewest presses of inputs to every destination
+// of a piece type, searching breadth-first from its spawn position on the
+// empty board.
+func finesseSearch(pt PieceType, empty *Board, inputs []finesseInput) map[footprint]int {
+	start := Piece{Type: pt, Rotation: Rot0, Pos: empty.SpawnPosition(pt)}
+	dist := map[Piece]int{start: 0}
+	queue := []Piece{start}
+	best := make(map[footprint]int)
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		fp := empty.footprint(&p)
+		if _, ok := best[fp]; !ok {
+			best[fp] = dist[p]
+		}
+		for _, in := range inputs {
+			next, ok := empty.press(p, in)
+			if _, seen := dist[next]; ok && !seen {
+				dist[next] = dist[p] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+	return best
+}
+
+// press returns where one press moves p.
+func (b *Board) press(p Piece, in finesseInput) (Piece, bool) {
+	switch in {
+	case inputCW:
+		next, _, ok := b.Rotate(&p, p.Rotation.CW())
+		return next, ok
+	case inputCCW:
+		next, _, ok := b.Rotate(&p, p.Rotation.CCW())
+		return next, ok
+	case input180:
+		next, _, ok := b.Rotate(&p, p.Rotation.Half())
+		return next, ok
+	}
+	step := -1
+	if in == inputRight || in == inputDASRight {
+		step = 1
+	}
+	next := p
+	for {
+		next.Pos.Col += step
+		if !b.ValidPosition(&next) {
+			next.Pos.Col -= step
+			break
+		}
+		if in == inputLeft || in == inputRight {
+			break
+		}
+	}
+	return next, next != p
+}
+
+// footprint returns the cells p covers once hard dropped on b.
+func (b *Board) footprint(p *Piece) footprint {
+	dropped := p.Clone()
+	dropped.Pos = b.GhostPosition(p)
+	var fp footprint
+	copy(fp[:], b.PieceCells(&dropped))
+	slices.SortFunc(fp[:], func(a, b Position) int {
+		if a.Row != b.Row {
+			return a.Row - b.Row
+		}
+		return a.Col - b.Col
+	})
+	return fp
+}
+
+// FinessePresses returns the fewest key presses that lock p where it is
+// on b, counting 180° turns if turn180 is set. It reports false when p
+// can't be dropped straight down from the spawn height, as with tucks and
+// spins, where finesse doesn't apply.
+func (b *Board) FinessePresses(p *Piece, turn180 bool) (int, bool) {
+	above := p.Clone()
+	for above.Pos.Row > b.SpawnPosition(p.Type).Row {
+		above.Pos.Row--
+		if !b.ValidPosition(&above) {
+			return 0, false
+		}
+	}
+	// On an empty board the piece falls to the floor; its columns and
+	// shape are what matters.
+	n, ok := finesseTableOf(b, turn180)[p.Type][b.flat().footprint(&above)]
+	return n, ok
+}
+
+// FinesseStats counts the pieces judged for finesse and the faults among
+// them: pieces placed with more presses than needed.
+type FinesseStats struct {
+	Pieces int `json:"pieces"`
+	Faults int `json:"faults"`
+}
+
+// Percent returns the share of judged pieces placed without a fault, or
+// 100 before any piece is judged.
+func (f FinesseStats) Percent() float64 {
+	if f.Pieces == 0 {
+		return 100
+	}
+	return 100 * float64(f.Pieces-f.Faults) / float64(f.Pieces)
+}
+
+// Destinations returns every distinct place a piece of type pt can be
+// hard dropped to on an empty board of b's size, as pieces resting on
+// the floor.
+func (b *Board) Destinations(pt PieceType) []Piece {
+	empty := b.empty()
+	var pieces []Piece
+	seen := make(map[footprint]bool)
+	for rot := Rot0; rot <= Rot3; rot++ {
+		for col := -3; col < b.Width; col++ {
+			p := Piece{Type: pt, Rotation: rot, Pos: Position{Row: BufferRows, Col: col}}
+			if !empty.ValidPosition(&p) {
+				continue
+			}
+			fp := empty.footprint(&p)
+			if seen[fp] {
+				continue
+			}
+			seen[fp] = true
+			p.Pos = empty.GhostPosition(&p)
+			pieces = append(pieces, p)
+		}
+	}
+	return pieces
+}
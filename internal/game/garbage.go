@@ -0,0 +1,132 @@
+package game
+
+// cheeseRows is how many garbage rows a garbage goal keeps on the board
+// while enough are left; boards shorter than twice that keep half their
+// height.
+const cheeseRows = 10
+
+// garbageSalt separates the garbage hole sequence from the piece sequence
+// of the same seed.
+const garbageSalt = 0x6a09e667f3bcc908
+
+// attackLines is the garbage sent by each clear type before bonuses.
+var attackLines = map[LineClearType]int{
+	ClearDouble:          1,
+	ClearTriple:          2,
+	ClearTetris:          4,
+	ClearTSpinSingle:     2,
+	ClearTSpinDouble:     4,
+	ClearTSpinTriple:     6,
+	ClearTSpinMiniDouble: 1,
+}
+
+// comboAttack is the extra garbage per combo count; longer combos use the
+// last entry.
+var comboAttack = []int{0, 0, 1, 1, 2, 2, 3, 3, 4, 4, 4, 5}
+
+// perfectClearAttack is the garbage sent by a perfect clear.
+const perfectClearAttack = 10
+
+// Attack returns the garbage lines a clear sends. combo is the number of
+// consecutive clears before this one.
+func Attack(info ClearInfo, combo int) int {
+	if info.Lines == 0 {
+		return 0
+	}
+	lines := attackLines[info.Type]
+	if info.BackToBack {
+		lines++
+	}
+	lines += comboAttack[min(combo, len(comboAttack)-1)]
+	if info.PerfectClear {
+		lines += perfectClearAttack
+	}
+	return lines
+}
+
+// ReceiveGarbage queues an attack of the given number of lines. Queued
+// garbage rises from the bottom the next time a piece locks without
+// clearing lines.
+func (e *Engine) ReceiveGarbage(lines int) {
+	if lines > 0 {
+		e.Garbage = append(e.Garbage, lines)
+	}
+}
+
+// PendingGarbage returns the number of queued garbage lines.
+func (e *Engine) PendingGarbage() int {
+	n := 0
+	for _, lines := range e.Garbage {
+		n += lines
+	}
+	return n
+}
+
+// TakeOutgoing returns the garbage sent since the last call and resets it.
+func (e *Engine) TakeOutgoing() int {
+	n := e.Outgoing
+	e.Outgoing = 0
+	return n
+}
+
+// cancelGarbage offsets queued garbage, oldest first, with an attack and
+// returns what is left of the attack.
+func (e *Engine) cancelGarbage(attack int) int {
+	for attack > 0 && len(e.Garbage) > 0 {
+		n := min(attack, e.Garbage[0])
+		attack -= n
+		e.Garbage[0] -= n
+		if e.Garbage[0] == 0 {
+			e.Garbage = e.Garbage[1:]
+		}
+	}
+	return attack
+}
+
+// AddGarbage pushes an attack of garbage lines up from the bottom of the
+// board. Each attack starts in a new hole column, which then moves with
+// probability GarbageMessiness between rows. It reports false if the board
+// was pushed out of the top.
+func (e *Engine) AddGarbage(lines int) bool {
+	holes := make([]int, lines)
+	for i := range holes {
+		switch {
+		case e.garbageHole < 0:
+			e.garbageHole = e.garbageRNG.intn(e.Board.Width)
+		case i == 0 || e.garbageRNG.float64() < e.GarbageMessiness:
+			// Move to one of the other columns.
+			e.garbageHole = (e.garbageHole + 1 + e.garbageRNG.intn(e.Board.Width-1)) % e.Board.Width
+		}
+		holes[i] = e.garbageHole
+	}
+	e.garbageAdded += lines
+	ok := e.Board.AddGarbage(holes)
+	e.emit(GarbageReceived{Lines: lines, Holes: holes})
+	return ok
+}
+
+// GarbageCleared returns how many garbage lines have been cleared.
+func (e *Engine) GarbageCleared() int {
+	return e.garbageAdded - e.Board.GarbageRows()
+}
+
+// topUpGarbage refills the board to cheeseRows garbage rows while the
+// garbage goal has lines left to add.
+func (e *Engine) topUpGarbage() bool {
+	rows := min(cheeseRows, e.Board.Height/2)
+	lines := min(rows-e.Board.GarbageRows(), e.GarbageGoal-e.garbageAdded)
+	if lines <= 0 {
+		return true
+	}
+	return e.AddGarbage(lines)
+}
+
+// insertGarbage moves all queued garbage onto the board.
+func (e *Engine) insertGarbage() bool {
+	ok := true
+	for _, lines := range e.Garbage {
+		ok = e.AddGarbage(lines) && ok
+	}
+	e.Garbage = nil
+	return ok
+}
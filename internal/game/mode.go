@@ -0,0 +1,150 @@
+package game
+
+import "time"
+
+// Mode identifies a game mode.
+type Mode int
+
+const (
+	// ModeMarathon is endless play for score.
+	ModeMarathon Mode = iota
+	// ModeSprint ends once a number of lines is cleared; the goal is time.
+	ModeSprint
+	// ModeUltra ends after a fixed time; the goal is score.
+	ModeUltra
+	// ModeCheese ends once a number of garbage lines is dug out; the goal
+	// is time.
+	ModeCheese
+	// ModeVersus is one side of a two-player match; the goal is to
+	// outlast the opponent.
+	ModeVersus
+	// ModeFinesse is the finesse trainer: every piece is played on an
+	// empty board, and the game never ends.
+	ModeFinesse
+)
+
+// ModeName returns the display name of a mode.
+func ModeName(m Mode) string {
+	switch m {
+	case ModeMarathon:
+		return "Marathon"
+	case ModeSprint:
+		return "Sprint"
+	case ModeUltra:
+		return "Ultra"
+	case ModeCheese:
+		return "Cheese Race"
+	case ModeVersus:
+		return "Versus"
+	case ModeFinesse:
+		return "Finesse"
+	default:
+		return "?"
+	}
+}
+
+// SprintGoals are the selectable Sprint line goals.
+var SprintGoals = []int{20, 40, 100}
+
+// UltraDurations are the selectable Ultra time limits in seconds.
+var UltraDurations = []int{60, 120, 180}
+
+// CheeseGoals are the selectable Cheese Race garbage line goals.
+var CheeseGoals = []int{10, 18, 100}
+
+// VersusRounds are the selectable best-of-N versus match lengths.
+var VersusRounds = []int{1, 3, 5}
+
+// Options configures a new game.
+type Options struct {
+	Mode         Mode
+	StartLevel   int
+	PreviewCount int
+	Seed         uint64
+
+	// LineGoal finishes the game once this many lines are cleared.
+	// Zero means no goal.
+	LineGoal int
+	// TimeLimit finishes the game once the game clock reaches it.
+	// Zero means no limit.
+	TimeLimit time.Duration
+	// GarbageMessiness is the probability that the hole of incoming
+	// garbage moves between rows of one attack: 0 gives clean attacks with
+	// one hole column each, 1 a new hole every row.
+	GarbageMessiness float64
+	// GarbageGoal fills the board with garbage and finishes the game once
+	// this many garbage lines are cleared. Zero means no goal.
+	GarbageGoal int
+	// Width and Height are the size of the visible board, clamped to the
+	// supported sizes. Zero means the standard 10x20.
+	Width, Height int
+	// Rotation is the rotation system; nil means SRS.
+	Rotation RotationSystem
+}
+
+// MarathonOptions returns the options for a marathon game.
+func MarathonOptions(startLevel, previewCount int, seed uint64) Options {
+	return Options{
+		Mode:         ModeMarathon,
+		StartLevel:   startLevel,
+		PreviewCount: previewCount,
+		Seed:         seed,
+	}
+}
+
+// SprintOptions returns the options for a sprint to the given line goal.
+// Sprints always start at level 1.
+func SprintOptions(lines, previewCount int, seed uint64) Options {
+	return Options{
+		Mode:         ModeSprint,
+		StartLevel:   1,
+		PreviewCount: previewCount,
+		Seed:         seed,
+		LineGoal:     lines,
+	}
+}
+
+// UltraOptions returns the options for an ultra game of the given length.
+func UltraOptions(limit time.Duration, previewCount int, seed uint64) Options {
+	return Options{
+		Mode:         ModeUltra,
+		StartLevel:   1,
+		PreviewCount: previewCount,
+		Seed:         seed,
+		TimeLimit:    limit,
+	}
+}
+
+// CheeseOptions returns the options for a cheese race digging through the
+// given number of garbage lines.
+func CheeseOptions(lines, previewCount int, seed uint64) Options {
+	return Options{
+		Mode:             ModeCheese,
+		StartLevel:       1,
+		PreviewCount:     previewCount,
+		Seed:             seed,
+		GarbageMessiness: 1,
+		GarbageGoal:      lines,
+	}
+}
+
+// VersusOptions returns the options for one player of a versus round.
+// Both players use the same options, so they get the same pieces.
+func VersusOptions(previewCount int, seed uint64) Options {
+	return Options{
+		Mode:         ModeVersus,
+		StartLevel:   1,
+		PreviewCount: previewCount,
+		Seed:         seed,
+	}
+}
+
+// FinesseOptions returns the options for the finesse trainer.
+func FinesseOptions(previewCount int, seed uint64) Options {
+	return Options{
+		Mode:         ModeFinesse,
+		StartLevel:   1,
+		PreviewCount: previewCount,
+		Seed:         seed,
+	}
+}
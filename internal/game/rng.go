@@ -0,0 +1,57 @@
+package game
+
+import "math/rand/v2"
+
+// RNGVersion identifies the randomizer algorithm. It must be bumped whenever
+// the piece sequence produced for a given seed changes, so that seeds and
+// recordings from older releases can be told apart.
+const RNGVersion = 1
+
+// rng is a SplitMix64 generator. It is implemented here rather than taken
+// from math/rand so that a seed yields the same sequence on every platform
+// and Go release.
+type rng struct {
+	state uint64
+}
+
+func newRNG(seed uint64) *rng {
+	return &rng{state: seed}
+}
+
+func (r *rng) next() uint64 {
+	r.state += 0x9e3779b97f4a7c15
+	z := r.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// intn returns a uniformly distributed value in [0, n).
+func (r *rng) intn(n int) int {
+	bound := uint64(n)
+	// Reject values from the incomplete final range to avoid modulo bias.
+	limit := ^uint64(0) - (^uint64(0)%bound+1)%bound
+	for {
+		v := r.next()
+		if v <= limit {
+			return int(v % bound)
+		}
+	}
+}
+
+// float64 returns a uniformly distributed value in [0, 1).
+func (r *rng) float64() float64 {
+	return float64(r.next()>>11) / (1 << 53)
+}
+
+// shuffle performs a Fisher-Yates shuffle of n elements.
+func (r *rng) shuffle(n int, swap func(i, j int)) {
+	for i := n - 1; i > 0; i-- {
+		swap(i, r.intn(i+1))
+	}
+}
+
+// RandomSeed returns a fresh seed for games started without one.
+func RandomSeed() uint64 {
+	return rand.Uint64()
+}
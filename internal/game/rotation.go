@@ -0,0 +1,382 @@
+package game
+
+import "slices"
+
+// RotationSystem decides what the pieces look like in each rotation and
+// where they may be kicked to when a turn is blocked.
+type RotationSystem interface {
+	// Name identifies the system in settings, replays and snapshots.
+	Name() string
+	// Shape returns the cells of a piece type in a rotation, as offsets
+	// from the top-left of its 4x4 box.
+	Shape(pt PieceType, r Rotation) []Position
+	// Kicks returns the offsets to try, in order, when p turns to
+	// rotation to on b, a quarter or a half turn away. The rotation fails
+	// if none fits.
+	Kicks(b *Board, p *Piece, to Rotation) []Position
+}
+
+// The rotation systems.
+var (
+	// SRS is the Super Rotation System of the guideline games, which has
+	// no 180° turns: they only fit in place.
+	SRS RotationSystem = srs{}
+	// SRSPlus is SRS with the symmetric I kicks and the 180° kicks of
+	// TETR.IO.
+	SRSPlus RotationSystem = srsPlus{}
+	// ARS is the Arika Rotation System of the TGM games: pieces sit flat
+	// side up and kick one cell right or left.
+	ARS RotationSystem = ars{}
+	// NRS is the Nintendo Rotation System of the NES and Game Boy games,
+	// which never kicks.
+	NRS RotationSystem = nrs{}
+)
+
+// RotationSystems lists every rotation system, the default first.
+var RotationSystems = []RotationSystem{SRS, SRSPlus, ARS, NRS}
+
+// RotationSystemNamed returns the rotation system with the given name.
+// The empty name is SRS, as in games from before there was a choice.
+func RotationSystemNamed(name string) (RotationSystem, bool) {
+	if name == "" {
+		return SRS, true
+	}
+	for _, rs := range RotationSystems {
+		if rs.Name() == name {
+			return rs, true
+		}
+	}
+	return nil, false
+}
+
+// shapes holds the cells of every piece type in each rotation.
+type shapes [PieceL + 1][4][]Position
+
+// srsShapes are the SRS rotations, also used by SRS+.
+var srsShapes = shapes{
+	PieceI: {
+		{{0, 0}, {0, 1}, {0, 2}, {0, 3}},
+		{{0, 2}, {1, 2}, {2, 2}, {3, 2}},
+		{{2, 0}, {2, 1}, {2, 2}, {2, 3}},
+		{{0, 1}, {1, 1}, {2, 1}, {3, 1}},
+	},
+	PieceO: {
+		{{0, 0}, {0, 1}, {1, 0}, {1, 1}},
+		{{0, 0}, {0, 1}, {1, 0}, {1, 1}},
+		{{0, 0}, {0, 1}, {1, 0}, {1, 1}},
+		{{0, 0}, {0, 1}, {1, 0}, {1, 1}},
+	},
+	PieceT: {
+		{{0, 1}, {1, 0}, {1, 1}, {1, 2}},
+		{{0, 1}, {1, 1}, {1, 2}, {2, 1}},
+		{{1, 0}, {1, 1}, {1, 2}, {2, 1}},
+		{{0, 1}, {1, 0}, {1, 1}, {2, 1}},
+	},
+	PieceS: {
+		{{0, 1}, {0, 2}, {1, 0}, {1, 1}},
+		{{0, 1}, {1, 1}, {1, 2}, {2, 2}},
+		{{1, 1}, {1, 2}, {2, 0}, {2, 1}},
+		{{0, 0}, {1, 0}, {1, 1}, {2, 1}},
+	},
+	PieceZ: {
+		{{0, 0}, {0, 1}, {1, 1}, {1, 2}},
+		{{0, 2}, {1, 1}, {1, 2}, {2, 1}},
+		{{1, 0}, {1, 1}, {2, 1}, {2, 2}},
+		{{0, 1}, {1, 0}, {1, 1}, {2, 0}},
+	},
+	PieceJ: {
+		{{0, 0}, {1, 0}, {1, 1}, {1, 2}},
+		{{0, 1}, {0, 2}, {1, 1}, {2, 1}},
+		{{1, 0}, {1, 1}, {1, 2}, {2, 2}},
+		{{0, 1}, {1, 1}, {2, 0}, {2, 1}},
+	},
+	PieceL: {
+		{{0, 2}, {1, 0}, {1, 1}, {1, 2}},
+		{{0, 1}, {1, 1}, {2, 1}, {2, 2}},
+		{{1, 0}, {1, 1}, {1, 2}, {2, 0}},
+		{{0, 0}, {0, 1}, {1, 1}, {2, 1}},
+	},
+}
+
+// Kick tables list the offsets to try per turn. Offsets are in board
+// coordinates, so a positive Row moves the piece down.
+type kickTable map[[2]Rotation][]Position
+
+// srsKicks is for the J, L, S, T and Z pieces.
+var srsKicks = kickTable{
+	{Rot0, Rot1}: {{0, 0}, {0, -1}, {-1, -1}, {2, 0}, {2, -1}},
+	{Rot1, Rot0}: {{0, 0}, {0, 1}, {1, 1}, {-2, 0}, {-2, 1}},
+	{Rot1, Rot2}: {{0, 0}, {0, 1}, {1, 1}, {-2, 0}, {-2, 1}},
+	{Rot2, Rot1}: {{0, 0}, {0, -1}, {-1, -1}, {2, 0}, {2, -1}},
+	{Rot2, Rot3}: {{0, 0}, {0, 1}, {-1, 1}, {2, 0}, {2, 1}},
+	{Rot3, Rot2}: {{0, 0}, {0, -1}, {1, -1}, {-2, 0}, {-2, -1}},
+	{Rot3, Rot0}: {{0, 0}, {0, -1}, {1, -1}, {-2, 0}, {-2, -1}},
+	{Rot0, Rot3}: {{0, 0}, {0, 1}, {-1, 1}, {2, 0}, {2, 1}},
+}
+
+// srsKicksI is for the I piece.
+var srsKicksI = kickTable{
+	{Rot0, Rot1}: {{0, 0}, {0, -2}, {0, 1}, {1, -2}, {-2, 1}},
+	{Rot1, Rot0}: {{0, 0}, {0, 2}, {0, -1}, {-1, 2}, {2, -1}},
+	{Rot1, Rot2}: {{0, 0}, {0, -1}, {0, 2}, {-2, -1}, {1, 2}},
+	{Rot2, Rot1}: {{0, 0}, {0, 1}, {0, -2}, {2, 1}, {-1, -2}},
+	{Rot2, Rot3}: {{0, 0}, {0, 2}, {0, -1}, {-1, 2}, {2, -1}},
+	{Rot3, Rot2}: {{0, 0}, {0, -2}, {0, 1}, {1, -2}, {-2, 1}},
+	{Rot3, Rot0}: {{0, 0}, {0, 1}, {0, -2}, {2, 1}, {-1, -2}},
+	{Rot0, Rot3}: {{0, 0}, {0, -1}, {0, 2}, {-2, -1}, {1, 2}},
+}
+
+// srsPlusKicksI is the I piece's table in SRS+, the same to either side
+// of a wall.
+var srsPlusKicksI = kickTable{
+	{Rot0, Rot1}: {{0, 0}, {0, 1}, {0, -2}, {1, -2}, {-2, 1}},
+	{Rot1, Rot0}: {{0, 0}, {0, -1}, {0, 2}, {2, -1}, {-1, 2}},
+	{Rot1, Rot2}: {{0, 0}, {0, -1}, {0, 2}, {-2, -1}, {1, 2}},
+	{Rot2, Rot1}: {{0, 0}, {0, -2}, {0, 1}, {-1, -2}, {2, 1}},
+	{Rot2, Rot3}: {{0, 0}, {0, 2}, {0, -1}, {-1, 2}, {2, -1}},
+	{Rot3, Rot2}: {{0, 0}, {0, 1}, {0, -2}, {-2, 1}, {1, -2}},
+	{Rot3, Rot0}: {{0, 0}, {0, 1}, {0, -2}, {2, 1}, {-1, -2}},
+	{Rot0, Rot3}: {{0, 0}, {0, -1}, {0, 2}, {1, 2}, {-2, -1}},
+}
+
+// kicks180 is the SRS+ table for 180° turns of every piece but O.
+var kicks180 = kickTable{
+	{Rot0, Rot2}: {{0, 0}, {-1, 0}, {-1, 1}, {-1, -1}, {0, 1}, {0, -1}},
+	{Rot2, Rot0}: {{0, 0}, {1, 0}, {1, -1}, {1, 1}, {0, -1}, {0, 1}},
+	{Rot1, Rot3}: {{0, 0}, {0, 1}, {-2, 1}, {-1, 1}, {-2, 0}, {-1, 0}},
+	{Rot3, Rot1}: {{0, 0}, {0, -1}, {-2, -1}, {-1, -1}, {-2, 0}, {-1, 0}},
+}
+
+// noKicks only tries the turn in place.
+var noKicks = []Position{{0, 0}}
+
+type srs struct{}
+
+func (srs) Name() string { return "SRS" }
+
+func (srs) Shape(pt PieceType, r Rotation) []Position {
+	return srsShapes[pt][r]
+}
+
+func (srs) Kicks(b *Board, p *Piece, to Rotation) []Position {
+	switch {
+	case p.Type == PieceO, to == p.Rotation.Half():
+		return noKicks
+	case p.Type == PieceI:
+		return kicksOf(srsKicksI, p.Rotation, to)
+	default:
+		return kicksOf(srsKicks, p.Rotation, to)
+	}
+}
+
+type srsPlus struct{ srs }
+
+func (srsPlus) Name() string { return "SRS+" }
+
+func (rs srsPlus) Kicks(b *Board, p *Piece, to Rotation) []Position {
+	switch {
+	case p.Type == PieceO:
+		return noKicks
+	case to == p.Rotation.Half():
+		return kicksOf(kicks180, p.Rotation, to)
+	case p.Type == PieceI:
+		return kicksOf(srsPlusKicksI, p.Rotation, to)
+	default:
+		return rs.srs.Kicks(b, p, to)
+	}
+}
+
+// kicksOf returns the kicks of a turn in a table, or only the turn in
+// place if the table has none for it.
+func kicksOf(t kickTable, from, to Rotation) []Position {
+	if kicks, ok := t[[2]Rotation{from, to}]; ok {
+		return kicks
+	}
+	return noKicks
+}
+
+// arsShapes are the TGM rotations: pieces lying flat keep to the bottom
+// of a 3x3 box, and S, Z and I have only two of them.
+var arsShapes = shapes{
+	PieceI: {
+		{{1, 0}, {1, 1}, {1, 2}, {1, 3}},
+		{{0, 2}, {1, 2}, {2, 2}, {3, 2}},
+		{{1, 0}, {1, 1}, {1, 2}, {1, 3}},
+		{{0, 2}, {1, 2}, {2, 2}, {3, 2}},
+	},
+	PieceO: {
+		{{1, 1}, {1, 2}, {2, 1}, {2, 2}},
+		{{1, 1}, {1, 2}, {2, 1}, {2, 2}},
+		{{1, 1}, {1, 2}, {2, 1}, {2, 2}},
+		{{1, 1}, {1, 2}, {2, 1}, {2, 2}},
+	},
+	PieceT: {
+		{{1, 0}, {1, 1}, {1, 2}, {2, 1}},
+		{{0, 1}, {1, 0}, {1, 1}, {2, 1}},
+		{{1, 1}, {2, 0}, {2, 1}, {2, 2}},
+		{{0, 1}, {1, 1}, {1, 2}, {2, 1}},
+	},
+	PieceS: {
+		{{1, 1}, {1, 2}, {2, 0}, {2, 1}},
+		{{0, 0}, {1, 0}, {1, 1}, {2, 1}},
+		{{1, 1}, {1, 2}, {2, 0}, {2, 1}},
+		{{0, 0}, {1, 0}, {1, 1}, {2, 1}},
+	},
+	PieceZ: {
+		{{1, 0}, {1, 1}, {2, 1}, {2, 2}},
+		{{0, 2}, {1, 1}, {1, 2}, {2, 1}},
+		{{1, 0}, {1, 1}, {2, 1}, {2, 2}},
+		{{0, 2}, {1, 1}, {1, 2}, {2, 1}},
+	},
+	PieceJ: {
+		{{1, 0}, {1, 1}, {1, 2}, {2, 2}},
+		{{0, 1}, {1, 1}, {2, 0}, {2, 1}},
+		{{1, 0}, {2, 0}, {2, 1}, {2, 2}},
+		{{0, 1}, {0, 2}, {1, 1}, {2, 1}},
+	},
+	PieceL: {
+		{{1, 0}, {1, 1}, {1, 2}, {2, 0}},
+		{{0, 0}, {0, 1}, {1, 1}, {2, 1}},
+		{{1, 2}, {2, 0}, {2, 1}, {2, 2}},
+		{{0, 1}, {1, 1}, {2, 1}, {2, 2}},
+	},
+}
+
+// arsKicks tries the turn in place, then one cell right, then left. Half
+// turns kick the same way.
+var arsKicks = []Position{{0, 0}, {0, 1}, {0, -1}}
+
+type ars struct{}
+
+func (ars) Name() string { return "ARS" }
+
+func (ars) Shape(pt PieceType, r Rotation) []Position {
+	return arsShapes[pt][r]
+}
+
+// Kicks never moves I or O. J, L and T don't kick either when the first
+// cell in their way, reading their 3x3 box row by row, is in its middle
+// column: this stops them climbing out of a well.
+func (ars) Kicks(b *Board, p *Piece, to Rotation) []Position {
+	switch p.Type {
+	case PieceI, PieceO:
+		return noKicks
+	case PieceJ, PieceL, PieceT:
+		if centerBlocked(b, p, arsShapes[p.Type][to]) {
+			return noKicks
+		}
+	}
+	return arsKicks
+}
+
+// centerBlocked reports whether the first cell of shape that is occupied
+// on b, reading p's box row by row, is in the middle column.
+func centerBlocked(b *Board, p *Piece, shape []Position) bool {
+	for r := 0; r < 3; r++ {
+		for c := 0; c < 3; c++ {
+			cell := Position{Row: r, Col: c}
+			if !slices.Contains(shape, cell) {
+				continue
+			}
+			if b.IsOccupied(Position{Row: p.Pos.Row + r, Col: p.Pos.Col + c}) {
+				return c == 1
+			}
+		}
+	}
+	return false
+}
+
+// nrsShapes are the NES rotations: J, L and T turn about the middle of
+// a 3x3 box, and S, Z and I flip between two rotations.
+var nrsShapes = shapes{
+	PieceI: {
+		{{2, 0}, {2, 1}, {2, 2}, {2, 3}},
+		{{0, 2}, {1, 2}, {2, 2}, {3, 2}},
+		{{2, 0}, {2, 1}, {2, 2}, {2, 3}},
+		{{0, 2}, {1, 2}, {2, 2}, {3, 2}},
+	},
+	PieceO: {
+		{{1, 1}, {1, 2}, {2, 1}, {2, 2}},
+		{{1, 1}, {1, 2}, {2, 1}, {2, 2}},
+		{{1, 1}, {1, 2}, {2, 1}, {2, 2}},
+		{{1, 1}, {1, 2}, {2, 1}, {2, 2}},
+	},
+	PieceT: {
+		{{1, 0}, {1, 1}, {1, 2}, {2, 1}},
+		{{0, 1}, {1, 0}, {1, 1}, {2, 1}},
+		{{0, 1}, {1, 0}, {1, 1}, {1, 2}},
+		{{0, 1}, {1, 1}, {1, 2}, {2, 1}},
+	},
+	PieceS: {
+		{{1, 1}, {1, 2}, {2, 0}, {2, 1}},
+		{{0, 1}, {1, 1}, {1, 2}, {2, 2}},
+		{{1, 1}, {1, 2}, {2, 0}, {2, 1}},
+		{{0, 1}, {1, 1}, {1, 2}, {2, 2}},
+	},
+	PieceZ: {
+		{{1, 0}, {1, 1}, {2, 1}, {2, 2}},
+		{{0, 2}, {1, 1}, {1, 2}, {2, 1}},
+		{{1, 0}, {1, 1}, {2, 1}, {2, 2}},
+		{{0, 2}, {1, 1}, {1, 2}, {2, 1}},
+	},
+	PieceJ: {
+		{{1, 0}, {1, 1}, {1, 2}, {2, 2}},
+		{{0, 1}, {1, 1}, {2, 0}, {2, 1}},
+		{{0, 0}, {1, 0}, {1, 1}, {1, 2}},
+		{{0, 1}, {0, 2}, {1, 1}, {2, 1}},
+	},
+	PieceL: {
+		{{1, 0}, {1, 1}, {1, 2}, {2, 0}},
+		{{0, 0}, {0, 1}, {1, 1}, {2, 1}},
+		{{0, 2}, {1, 0}, {1, 1}, {1, 2}},
+		{{0, 1}, {1, 1}, {2, 1}, {2, 2}},
+	},
+}
+
+type nrs struct{}
+
+func (nrs) Name() string { return "NRS" }
+
+func (nrs) Shape(pt PieceType, r Rotation) []Position {
+	return nrsShapes[pt][r]
+}
+
+func (nrs) Kicks(b *Board, p *Piece, to Rotation) []Position {
+	return noKicks
+}
+
+// tPoint returns the middle cell of a T shape and the direction it points
+// in, as a one-cell step.
+func tPoint(shape []Position) (center, point Position) {
+	for _, c := range shape {
+		n := 0
+		for _, o := range shape {
+			dr, dc := o.Row-c.Row, o.Col-c.Col
+			if dr*dr+dc*dc == 1 {
+				n++
+			}
+		}
+		if n == 3 {
+			center = c
+		}
+	}
+	for _, c := range shape {
+		// The point is the only cell without one opposite it.
+		opposite := Position{Row: 2*center.Row - c.Row, Col: 2*center.Col - c.Col}
+		if c != center && !slices.Contains(shape, opposite) {
+			point = Position{Row: c.Row - center.Row, Col: c.Col - center.Col}
+		}
+	}
+	return center, point
+}
+
+// TDown returns the rotation in which a T points down in a rotation
+// system, the way it goes into a T-Spin Double slot.
+func TDown(rs RotationSystem) Rotation {
+	for r := Rot0; r <= Rot3; r++ {
+		if _, point := tPoint(rs.Shape(PieceT, r)); point.Row == 1 {
+			return r
+		}
+	}
+	return Rot2
+}
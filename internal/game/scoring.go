@@ -26,58 +26,76 @@ func (s *Scorer) AddHardDrop(cells int) {
 	s.Score += cells * 2
 }
 
-// AddLineClear processes a line clear event and returns the clear type.
-func (s *Scorer) AddLineClear(linesCleared int, isTSpin bool) LineClearType {
-	if linesCleared == 0 {
-		s.Combo = 0
-		return ClearNone
-	}
+// clearPoints are the base points per clear type, before the level
+// multiplier.
+var clearPoints = map[LineClearType]int{
+	ClearSingle:          100,
+	ClearDouble:          300,
+	ClearTriple:          500,
+	ClearTetris:          800,
+	ClearTSpin:           400,
+	ClearTSpinSingle:     800,
+	ClearTSpinDouble:     1200,
+	ClearTSpinTriple:     1600,
+	ClearTSpinMini:       100,
+	ClearTSpinMiniSingle: 200,
+	ClearTSpinMiniDouble: 400,
+}
 
-	var clearType LineClearType
-	var basePoints int
-
-	if isTSpin {
-		switch linesCleared {
-		case 1:
-			clearType = ClearTSpinSingle
-			basePoints = 800
-		case 2:
-			clearType = ClearTSpinDouble
-			basePoints = 1200
-		case 3:
-			clearType = ClearTSpinTriple
-			basePoints = 1600
-		}
-	} else {
-		switch linesCleared {
-		case 1:
-			clearType = ClearSingle
-			basePoints = 100
-		case 2:
-			clearType = ClearDouble
-			basePoints = 300
-		case 3:
-			clearType = ClearTriple
-			basePoints = 500
-		case 4:
-			clearType = ClearTetris
-			basePoints = 800
+// classifyClear returns the clear type for a lock.
+func classifyClear(linesCleared int, tspin TSpinKind) LineClearType {
+	switch tspin {
+	case TSpinFull:
+		return [...]LineClearType{ClearTSpin, ClearTSpinSingle, ClearTSpinDouble, ClearTSpinTriple}[min(linesCleared, 3)]
+	case TSpinMini:
+		// A Mini can't clear more than two lines; treat more as a T-Spin.
+		if linesCleared > 2 {
+			return ClearTSpinTriple
 		}
+		return [...]LineClearType{ClearTSpinMini, ClearTSpinMiniSingle, ClearTSpinMiniDouble}[linesCleared]
+	default:
+		return [...]LineClearType{ClearNone, ClearSingle, ClearDouble, ClearTriple, ClearTetris}[min(linesCleared, 4)]
+	}
+}
+
+// IsDifficultClear reports whether a clear type continues a back-to-back
+// chain: Tetrises and T-Spins (including Minis) that clear lines.
+func IsDifficultClear(t LineClearType) bool {
+	switch t {
+	case ClearTetris, ClearTSpinSingle, ClearTSpinDouble, ClearTSpinTriple,
+		ClearTSpinMiniSingle, ClearTSpinMiniDouble:
+		return true
+	default:
+		return false
+	}
+}
+
+// AddLineClear processes a lock and returns the clear type. Line-less
+// T-Spins score but leave the combo reset and the back-to-back chain
+// untouched. perfectClear adds the all-clear bonus for clears that empty
+// the board.
+func (s *Scorer) AddLineClear(linesCleared int, tspin TSpinKind, perfectClear bool) LineClearType {
+	clearType := classifyClear(linesCleared, tspin)
+	if linesCleared == 0 {
+		s.Combo = 0
+		s.Score += clearPoints[clearType] * s.Level
+		return clearType
 	}
 
-	points := basePoints * s.Level
+	points := clearPoints[clearType] * s.Level
 
 	// Back-to-back bonus for Tetris or T-Spin clears.
-	isDifficult := clearType == ClearTetris || isTSpin
+	isDifficult := IsDifficultClear(clearType)
 	if isDifficult && s.BackToBack {
 		points = points * 3 / 2
 	}
-	if isDifficult {
-		s.BackToBack = true
-	} else {
-		s.BackToBack = false
+
+	if perfectClear {
+		points += perfectClearBonus(linesCleared, clearType == ClearTetris && s.BackToBack) * s.Level
 	}
 
+	s.BackToBack = isDifficult
+
 	// Combo bonus.
 	if s.Combo > 0 {
 		points += 50 * s.Combo * s.Level
@@ -96,6 +114,23 @@ func (s *Scorer) AddLineClear(linesCleared int, isTSpin bool) LineClearType {
 	return clearType
 }
 
+// perfectClearBonus returns the guideline all-clear bonus before the level
+// multiplier.
+func perfectClearBonus(linesCleared int, backToBackTetris bool) int {
+	switch {
+	case backToBackTetris:
+		return 3200
+	case linesCleared == 1:
+		return 800
+	case linesCleared == 2:
+		return 1200
+	case linesCleared == 3:
+		return 1800
+	default:
+		return 2000
+	}
+}
+
 // GravityInterval returns the gravity interval in seconds for the current level.
 // Formula: (0.8 - ((level-1) * 0.007)) ^ (level-1)
 func (s *Scorer) GravityInterval() float64 {
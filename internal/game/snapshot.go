@@ -0,0 +1,249 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Snapshot is the complete state of a game: unlike DisplayState it holds
+// everything needed to go on playing, down to the position of the random
+// number generators, so a restored game continues exactly as the original
+// would have.
+type Snapshot struct {
+	RNGVersion int `json:"rng_version"`
+
+	Mode             Mode          `json:"mode"`
+	StartLevel       int           `json:"start_level"`
+	PreviewCount     int           `json:"preview_count"`
+	Seed             uint64        `json:"seed"`
+	LineGoal         int           `json:"line_goal,omitempty"`
+	TimeLimit        time.Duration `json:"time_limit,omitempty"`
+	GarbageMessiness float64       `json:"garbage_messiness,omitempty"`
+	GarbageGoal      int           `json:"garbage_goal,omitempty"`
+	// Width and Height are the visible board size; zero means standard.
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	// Rotation names the rotation system; empty means SRS.
+	Rotation string `json:"rotation,omitempty"`
+
+	State GameState `json:"state"`
+	// Board holds the board rows, top (buffer) row first.
+	Board     [][]CellColor `json:"board"`
+	Current   *Piece        `json:"current,omitempty"`
+	HoldPiece *PieceType    `json:"hold,omitempty"`
+	HoldUsed  bool          `json:"hold_used,omitempty"`
+	// Queue is what is left of the bag and BagRNG the state of its
+	// generator.
+	Queue  []PieceType `json:"queue"`
+	BagRNG uint64      `json:"bag_rng"`
+	Scorer Scorer      `json:"scorer"`
+
+	Clock       time.Duration `json:"clock"`
+	LockTimer   time.Duration `json:"lock_timer"`
+	LockResets  int           `json:"lock_resets"`
+	LockStarted bool          `json:"lock_started,omitempty"`
+	NextGravity time.Duration `json:"next_gravity"`
+
+	LastMoveWasRotation bool `json:"last_move_was_rotation,omitempty"`
+	LastKick            int  `json:"last_kick,omitempty"`
+
+	PiecesPlaced int       `json:"pieces"`
+	Garbage      []int     `json:"garbage,omitempty"`
+	Outgoing     int       `json:"outgoing,omitempty"`
+	GarbageRNG   uint64    `json:"garbage_rng"`
+	GarbageHole  int       `json:"garbage_hole"`
+	GarbageAdded int       `json:"garbage_added,omitempty"`
+	LastClear    ClearInfo `json:"last_clear"`
+}
+
+// Options returns the options of the snapshot's game. An unknown
+// rotation system is left nil, which is SRS.
+func (s Snapshot) Options() Options {
+	rs, _ := RotationSystemNamed(s.Rotation)
+	return Options{
+		Mode:             s.Mode,
+		StartLevel:       s.StartLevel,
+		PreviewCount:     s.PreviewCount,
+		Seed:             s.Seed,
+		LineGoal:         s.LineGoal,
+		TimeLimit:        s.TimeLimit,
+		GarbageMessiness: s.GarbageMessiness,
+		GarbageGoal:      s.GarbageGoal,
+		Width:            s.Width,
+		Height:           s.Height,
+		Rotation:         rs,
+	}
+}
+
+// Snapshot captures the state of the engine. The result shares nothing
+// with the engine.
+func (e *Engine) Snapshot() Snapshot {
+	opts := e.Options()
+	s := Snapshot{
+		RNGVersion:       RNGVersion,
+		Mode:             opts.Mode,
+		StartLevel:       opts.StartLevel,
+		PreviewCount:     opts.PreviewCount,
+		Seed:             opts.Seed,
+		LineGoal:         opts.LineGoal,
+		TimeLimit:        opts.TimeLimit,
+		GarbageMessiness: opts.GarbageMessiness,
+		GarbageGoal:      opts.GarbageGoal,
+		Width:            e.Board.Width,
+		Height:           e.Board.Height,
+		Rotation:         e.Board.System.Name(),
+
+		State:    e.State,
+		Board:    make([][]CellColor, e.Board.Rows()),
+		HoldUsed: e.HoldUsed,
+		Queue:    append([]PieceType(nil), e.Bag.pieces...),
+		BagRNG:   e.Bag.rng.state,
+		Scorer:   *e.Scorer,
+
+		Clock:       e.Clock,
+		LockTimer:   e.LockTimer,
+		LockResets:  e.LockResets,
+		LockStarted: e.LockStarted,
+		NextGravity: e.nextGravity,
+
+		LastMoveWasRotation: e.LastMoveWasRotation,
+		LastKick:            e.lastKick,
+
+		PiecesPlaced: e.PiecesPlaced,
+		Garbage:      append([]int(nil), e.Garbage...),
+		Outgoing:     e.Outgoing,
+		GarbageRNG:   e.garbageRNG.state,
+		GarbageHole:  e.garbageHole,
+		GarbageAdded: e.garbageAdded,
+		LastClear:    e.LastClear,
+	}
+	for r := range s.Board {
+		s.Board[r] = append([]CellColor(nil), e.Board.Cells[r]...)
+	}
+	if e.Current != nil {
+		p := e.Current.Clone()
+		s.Current = &p
+	}
+	if e.HoldPiece != nil {
+		h := *e.HoldPiece
+		s.HoldPiece = &h
+	}
+	return s
+}
+
+// Restore puts the engine in the state of s. The engine keeps its
+// subscribers, which get no events for the jump.
+func (e *Engine) Restore(s Snapshot) error {
+	if s.RNGVersion != RNGVersion {
+		return fmt.Errorf("snapshot from randomizer version %d, this build has %d", s.RNGVersion, RNGVersion)
+	}
+	width, height := ClampBoardSize(s.Width, s.Height)
+	if (s.Width != 0 && width != s.Width) || (s.Height != 0 && height != s.Height) {
+		return fmt.Errorf("snapshot board is %dx%d, outside the supported sizes", s.Width, s.Height)
+	}
+	rs, ok := RotationSystemNamed(s.Rotation)
+	if !ok {
+		return fmt.Errorf("snapshot uses unknown rotation system %q", s.Rotation)
+	}
+	board := NewBoard(width, height)
+	board.System = rs
+	if len(s.Board) != board.Rows() {
+		return fmt.Errorf("snapshot board has %d rows, want %d", len(s.Board), board.Rows())
+	}
+	for r, row := range s.Board {
+		if len(row) != width {
+			return fmt.Errorf("snapshot board row %d has %d cells, want %d", r, len(row), width)
+		}
+		copy(board.Cells[r], row)
+	}
+	if err := s.checkPieces(); err != nil {
+		return err
+	}
+	if s.GarbageHole >= width {
+		return fmt.Errorf("snapshot garbage hole %d is off the board", s.GarbageHole)
+	}
+	if s.State == StatePlaying {
+		// Only a game that has ended is without a current piece.
+		if s.Current == nil {
+			return errors.New("snapshot game in play has no current piece")
+		}
+		if !board.ValidPosition(s.Current) {
+			return errors.New("snapshot piece overlaps the board")
+		}
+	}
+
+	e.Board = board
+	e.Bag = &Bag{pieces: append([]PieceType(nil), s.Queue...), rng: newRNG(s.BagRNG)}
+	e.Scorer = &Scorer{}
+	*e.Scorer = s.Scorer
+	e.State = s.State
+	e.Current = nil
+	if s.Current != nil {
+		p := s.Current.Clone()
+		e.Current = &p
+	}
+	e.HoldPiece = nil
+	if s.HoldPiece != nil {
+		h := *s.HoldPiece
+		e.HoldPiece = &h
+	}
+	e.HoldUsed = s.HoldUsed
+	e.PreviewCount = s.PreviewCount
+	e.Seed = s.Seed
+	e.Mode = s.Mode
+	e.LineGoal = s.LineGoal
+	e.TimeLimit = s.TimeLimit
+	e.startLevel = s.StartLevel
+
+	e.Clock = s.Clock
+	e.LockTimer = s.LockTimer
+	e.LockResets = s.LockResets
+	e.LockStarted = s.LockStarted
+	e.nextGravity = s.NextGravity
+	e.LastMoveWasRotation = s.LastMoveWasRotation
+	e.lastKick = s.LastKick
+
+	e.PiecesPlaced = s.PiecesPlaced
+	e.Garbage = append([]int(nil), s.Garbage...)
+	e.Outgoing = s.Outgoing
+	e.GarbageMessiness = s.GarbageMessiness
+	e.GarbageGoal = s.GarbageGoal
+	e.garbageRNG = newRNG(s.GarbageRNG)
+	e.garbageHole = s.GarbageHole
+	e.garbageAdded = s.GarbageAdded
+	e.LastClear = s.LastClear
+	return nil
+}
+
+// checkPieces reports an error for a piece type or rotation the engine
+// doesn't have, which would otherwise crash it on first use.
+func (s Snapshot) checkPieces() error {
+	valid := func(pt PieceType) bool { return pt >= PieceI && pt <= PieceL }
+	if s.Current != nil {
+		if !valid(s.Current.Type) {
+			return fmt.Errorf("snapshot piece has unknown type %d", s.Current.Type)
+		}
+		if s.Current.Rotation < Rot0 || s.Current.Rotation > Rot3 {
+			return fmt.Errorf("snapshot piece has unknown rotation %d", s.Current.Rotation)
+		}
+	}
+	if s.HoldPiece != nil && !valid(*s.HoldPiece) {
+		return fmt.Errorf("snapshot hold has unknown type %d", *s.HoldPiece)
+	}
+	for _, pt := range s.Queue {
+		if !valid(pt) {
+			return fmt.Errorf("snapshot queue has unknown type %d", pt)
+		}
+	}
+	return nil
+}
+
+// NewEngineFromSnapshot creates an engine in the state of s.
+func NewEngineFromSnapshot(s Snapshot) (*Engine, error) {
+	e := &Engine{}
+	if err := e.Restore(s); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
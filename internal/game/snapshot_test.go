@@ -0,0 +1,129 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+func TestRestoreRejectsBadPieces(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(s *Snapshot)
+	}{
+		{"current type", func(s *Snapshot) { s.Current.Type = PieceL + 1 }},
+		{"negative current type", func(s *Snapshot) { s.Current.Type = -1 }},
+		{"current rotation", func(s *Snapshot) { s.Current.Rotation = 4 }},
+		{"hold", func(s *Snapshot) { h := PieceType(99); s.HoldPiece = &h }},
+		{"queue", func(s *Snapshot) { s.Queue[0] = 7 }},
+		{"garbage hole", func(s *Snapshot) { s.GarbageHole = DefaultWidth }},
+		{"no current piece", func(s *Snapshot) { s.Current = nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewEngine(MarathonOptions(1, 5, 1)).Snapshot()
+			tt.corrupt(&s)
+			if _, err := NewEngineFromSnapshot(s); err == nil {
+				t.Error("restored a corrupt snapshot")
+			}
+		})
+	}
+
+	s := NewEngine(MarathonOptions(1, 5, 1)).Snapshot()
+	if _, err := NewEngineFromSnapshot(s); err != nil {
+		t.Errorf("restoring a fresh snapshot: %v", err)
+	}
+}
+
+// play places up to n pieces on e with random turns, moves and soft
+// drops and time passing between inputs, from a generator seeded with
+// seed.
+func play(e *Engine, seed uint64, n int) {
+	r := rand.New(rand.NewPCG(seed, 0))
+	wait := func() { e.Advance(time.Duration(r.IntN(150)) * time.Millisecond) }
+	turns := []func() bool{e.RotateCW, e.RotateCCW, e.Rotate180}
+	for range n {
+		if e.State != StatePlaying {
+			return
+		}
+		if r.IntN(8) == 0 {
+			e.Hold()
+		}
+		turns[r.IntN(len(turns))]()
+		wait()
+		move := e.MoveRight
+		if r.IntN(2) == 0 {
+			move = e.MoveLeft
+		}
+		for range r.IntN(6) {
+			move()
+			wait()
+		}
+		if r.IntN(4) == 0 {
+			e.SoftDrop()
+			wait()
+		}
+		e.HardDrop()
+	}
+}
+
+// snapshotJSON returns the snapshot of e as JSON, the form it is kept in.
+func snapshotJSON(t *testing.T, e *Engine) []byte {
+	t.Helper()
+	data, err := json.Marshal(e.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	opts := MarathonOptions(1, 5, 21)
+	opts.Rotation = SRSPlus
+	e := NewEngine(opts)
+	play(e, 1, 8)
+
+	// Catch the game with a piece held, garbage on the way and the
+	// current piece on the ground, part way through its lock delay.
+	if e.HoldPiece == nil {
+		e.Hold()
+	}
+	e.ReceiveGarbage(2)
+	e.ReceiveGarbage(1)
+	for e.SoftDrop() {
+	}
+	for i := 0; i < 200 && !e.LockStarted; i++ {
+		e.Advance(10 * time.Millisecond)
+	}
+	e.Advance(LockDelay / 2)
+	if e.State != StatePlaying || e.HoldPiece == nil || !e.LockStarted || len(e.Garbage) == 0 {
+		t.Fatalf("game not in the state under test: state %v, hold %v, lock %v, garbage %v",
+			e.State, e.HoldPiece, e.LockStarted, e.Garbage)
+	}
+
+	data := snapshotJSON(t, e)
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := NewEngineFromSnapshot(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := snapshotJSON(t, restored); !bytes.Equal(got, data) {
+		t.Fatalf("restored game differs:\n got %s\nwant %s", got, data)
+	}
+
+	// Both go on to play exactly the same game.
+	placed := e.PiecesPlaced
+	play(e, 2, 100)
+	play(restored, 2, 100)
+	if e.PiecesPlaced < placed+8 {
+		t.Errorf("only %d pieces played after the snapshot", e.PiecesPlaced-placed)
+	}
+	if got, want := snapshotJSON(t, restored), snapshotJSON(t, e); !bytes.Equal(got, want) {
+		t.Errorf("restored game played differently:\n got %s\nwant %s", got, want)
+	}
+}
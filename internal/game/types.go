@@ -1,5 +1,7 @@
 package game
 
+import "time"
+
 // CellColor represents the color type of a cell on the board.
 type CellColor int
 
@@ -13,6 +15,7 @@ const (
 	ColorJ
 	ColorL
 	ColorGhost
+	ColorGarbage
 )
 
 // GameState represents the current state of the game.
@@ -22,6 +25,8 @@ const (
 	StatePlaying GameState = iota
 	StatePaused
 	StateGameOver
+	// StateFinished means the mode's goal was reached.
+	StateFinished
 )
 
 // LineClearType categorizes how lines were cleared.
@@ -36,8 +41,63 @@ const (
 	ClearTSpinSingle
 	ClearTSpinDouble
 	ClearTSpinTriple
+	ClearTSpin
+	ClearTSpinMini
+	ClearTSpinMiniSingle
+	ClearTSpinMiniDouble
+)
+
+// ClearName returns the display name of a clear type.
+func ClearName(t LineClearType) string {
+	switch t {
+	case ClearSingle:
+		return "Single"
+	case ClearDouble:
+		return "Double"
+	case ClearTriple:
+		return "Triple"
+	case ClearTetris:
+		return "Tetris"
+	case ClearTSpinSingle:
+		return "T-Spin Single"
+	case ClearTSpinDouble:
+		return "T-Spin Double"
+	case ClearTSpinTriple:
+		return "T-Spin Triple"
+	case ClearTSpin:
+		return "T-Spin"
+	case ClearTSpinMini:
+		return "T-Spin Mini"
+	case ClearTSpinMiniSingle:
+		return "Mini Single"
+	case ClearTSpinMiniDouble:
+		return "Mini Double"
+	default:
+		return ""
+	}
+}
+
+// TSpinKind classifies a T piece lock.
+type TSpinKind int
+
+const (
+	TSpinNone TSpinKind = iota
+	TSpinMini
+	TSpinFull
 )
 
+// ClearInfo describes a line clear or a line-less T-Spin.
+type ClearInfo struct {
+	Type  LineClearType
+	Lines int
+	// PerfectClear is set when the clear left the board empty.
+	PerfectClear bool
+	// BackToBack is set when the clear earned the back-to-back bonus.
+	BackToBack bool
+	// Time is the game clock at the clear.
+	Time time.Duration
+}
+
 // Position represents a row/column coordinate.
 type Position struct {
 	Row int
@@ -54,6 +114,21 @@ const (
 	Rot3
 )
 
+// CW returns the rotation a clockwise turn leads to.
+func (r Rotation) CW() Rotation {
+	return (r + 1) % 4
+}
+
+// CCW returns the rotation a counter-clockwise turn leads to.
+func (r Rotation) CCW() Rotation {
+	return (r + 3) % 4
+}
+
+// Half returns the rotation a 180° turn leads to.
+func (r Rotation) Half() Rotation {
+	return (r + 2) % 4
+}
+
 // PieceType identifies a tetromino piece.
 type PieceType int
 
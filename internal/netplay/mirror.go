@@ -0,0 +1,37 @@
+package netplay
+
+import (
+	"github.com/meszmate/briks/internal/game"
+	"github.com/meszmate/briks/internal/replay"
+)
+
+// Mirror re-simulates the opponent's game from the messages it streams.
+// It trails the real game by the network latency but never diverges from
+// it, since the engine is deterministic.
+type Mirror struct {
+	Engine *game.Engine
+}
+
+// NewMirror creates a mirror of a round started with opts.
+func NewMirror(opts game.Options) *Mirror {
+	return &Mirror{Engine: game.NewEngine(opts)}
+}
+
+// Apply advances the mirror to the time of an input, garbage or clock
+// message and applies it. Other messages are ignored.
+func (m *Mirror) Apply(msg Message) {
+	switch msg.Type {
+	case MsgInput, MsgGarbage, MsgClock:
+	default:
+		return
+	}
+	if msg.Time > m.Engine.Clock {
+		m.Engine.Advance(msg.Time - m.Engine.Clock)
+	}
+	switch msg.Type {
+	case MsgInput:
+		replay.Apply(m.Engine, msg.Action)
+	case MsgGarbage:
+		m.Engine.ReceiveGarbage(msg.Lines)
+	}
+}
@@ -0,0 +1,286 @@
+package netplay
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/meszmate/briks/internal/config"
+	"github.com/meszmate/briks/internal/game"
+	"github.com/meszmate/briks/internal/replay"
+)
+
+// waitTimeout bounds every wait for a message in these tests.
+const waitTimeout = 5 * time.Second
+
+// listen starts a loopback listener on a free port.
+func listen(t *testing.T) net.Listener {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	return ln
+}
+
+// pair connects a host and a guest session in-process.
+func pair(t *testing.T, bestOf int) (host, guest *Session) {
+	t.Helper()
+	ln := listen(t)
+	accepted := make(chan *Session, 1)
+	errs := make(chan error, 1)
+	go func() {
+		s, err := Accept(ln, bestOf)
+		if err != nil {
+			errs <- err
+			return
+		}
+		accepted <- s
+	}()
+	guest, err := Dial(ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { guest.Close() })
+	select {
+	case host = <-accepted:
+	case err := <-errs:
+		t.Fatal(err)
+	case <-time.After(waitTimeout):
+		t.Fatal("timed out accepting")
+	}
+	t.Cleanup(func() { host.Close() })
+	return host, guest
+}
+
+// next returns the next message of a session.
+func next(t *testing.T, s *Session) Message {
+	t.Helper()
+	select {
+	case m, ok := <-s.Messages():
+		if !ok {
+			t.Fatal("messages closed")
+		}
+		return m
+	case <-time.After(waitTimeout):
+		t.Fatal("timed out waiting for a message")
+	}
+	return Message{}
+}
+
+func TestHandshake(t *testing.T) {
+	host, guest := pair(t, 3)
+	if !host.Host || guest.Host {
+		t.Errorf("Host = %v/%v, want true/false", host.Host, guest.Host)
+	}
+	if host.BestOf != 3 || guest.BestOf != 3 {
+		t.Errorf("BestOf = %d/%d, want 3", host.BestOf, guest.BestOf)
+	}
+
+	const seed = 0xdeadbeef12345678
+	if err := host.Start(seed); err != nil {
+		t.Fatal(err)
+	}
+	m := next(t, guest)
+	if m.Type != MsgStart || m.Seed != seed {
+		t.Errorf("guest got %+v, want start with seed %#x", m, uint64(seed))
+	}
+}
+
+func TestAcceptRejectsIncompatible(t *testing.T) {
+	tests := []struct {
+		name  string
+		hello Message
+	}{
+		{"protocol", Message{Type: MsgHello, Version: ProtocolVersion + 1, RNGVersion: game.RNGVersion}},
+		{"rng", Message{Type: MsgHello, Version: ProtocolVersion, RNGVersion: game.RNGVersion + 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln := listen(t)
+			accepted := make(chan error, 1)
+			go func() {
+				_, err := Accept(ln, 1)
+				accepted <- err
+			}()
+
+			c, err := net.Dial("tcp", ln.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+			c.SetDeadline(time.Now().Add(waitTimeout))
+			cn := newConn(c)
+			if err := cn.send(tt.hello); err != nil {
+				t.Fatal(err)
+			}
+			reply, err := cn.receive()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reply.Type != MsgReject || reply.Error == "" {
+				t.Errorf("reply = %+v, want a reject with a reason", reply)
+			}
+
+			// The host keeps waiting for a compatible opponent.
+			guest, err := Dial(ln.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer guest.Close()
+			select {
+			case err := <-accepted:
+				if err != nil {
+					t.Fatal(err)
+				}
+			case <-time.After(waitTimeout):
+				t.Fatal("timed out accepting")
+			}
+		})
+	}
+}
+
+func TestDialRejectsIncompatible(t *testing.T) {
+	tests := []struct {
+		name    string
+		welcome Message
+	}{
+		{"protocol", Message{Type: MsgWelcome, Version: ProtocolVersion + 1, RNGVersion: game.RNGVersion, BestOf: 1}},
+		{"rng", Message{Type: MsgWelcome, Version: ProtocolVersion, RNGVersion: game.RNGVersion + 1, BestOf: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln := listen(t)
+			go func() {
+				c, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				defer c.Close()
+				cn := newConn(c)
+				if _, err := cn.receive(); err == nil {
+					cn.send(tt.welcome)
+				}
+			}()
+
+			_, err := Dial(ln.Addr().String())
+			var reject *rejectError
+			if !errors.As(err, &reject) {
+				t.Fatalf("Dial error = %v, want an incompatible opponent", err)
+			}
+		})
+	}
+}
+
+// script is the inputs the guest plays in TestMirror, repeated.
+var script = []config.Action{
+	config.ActionMoveLeft, config.ActionRotateCW, config.ActionHardDrop,
+	config.ActionMoveRight, config.ActionMoveRight, config.ActionRotateCCW, config.ActionHardDrop,
+	config.ActionHold, config.ActionRotate180, config.ActionSoftDrop, config.ActionHardDrop,
+	config.ActionMoveLeft, config.ActionMoveLeft, config.ActionMoveLeft, config.ActionHardDrop,
+}
+
+// TestMirror plays a round on the guest, with garbage attacks from the
+// host, and checks that the host's mirror ends up with the same game.
+func TestMirror(t *testing.T) {
+	host, guest := pair(t, 1)
+	const seed = 42
+	opts := game.VersusOptions(5, seed)
+	if err := host.Start(seed); err != nil {
+		t.Fatal(err)
+	}
+	if m := next(t, guest); m.Type != MsgStart {
+		t.Fatalf("guest got %q, want start", m.Type)
+	}
+
+	e := game.NewEngine(opts)
+	for i := 0; i < 120 && e.State == game.StatePlaying; i++ {
+		if i%20 == 10 {
+			if err := host.Attack(2); err != nil {
+				t.Fatal(err)
+			}
+			m := next(t, guest)
+			if m.Type != MsgAttack || m.Lines != 2 {
+				t.Fatalf("guest got %+v, want an attack of 2 lines", m)
+			}
+			e.ReceiveGarbage(m.Lines)
+			if err := guest.Garbage(e.Clock, m.Lines); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		e.Advance(50 * time.Millisecond)
+		if err := guest.Clock(e.Clock); err != nil {
+			t.Fatal(err)
+		}
+		at, action := e.Clock, script[i%len(script)]
+		if replay.Apply(e, action) {
+			if err := guest.Input(at, action); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// Closing says goodbye after everything sent before.
+	if err := guest.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if e.Board.GarbageRows() == 0 && e.GarbageCleared() == 0 {
+		t.Fatal("no garbage reached the board")
+	}
+
+	mirror := NewMirror(opts)
+	for m := next(t, host); m.Type != MsgBye; m = next(t, host) {
+		mirror.Apply(m)
+	}
+	if !mirror.Engine.Board.Equal(e.Board) {
+		t.Error("mirror board differs from the guest's")
+	}
+	if mirror.Engine.Scorer.Score != e.Scorer.Score || mirror.Engine.PiecesPlaced != e.PiecesPlaced {
+		t.Errorf("mirror score %d after %d pieces, guest %d after %d",
+			mirror.Engine.Scorer.Score, mirror.Engine.PiecesPlaced, e.Scorer.Score, e.PiecesPlaced)
+	}
+	if mirror.Engine.State != e.State {
+		t.Errorf("mirror state %v, guest %v", mirror.Engine.State, e.State)
+	}
+}
+
+func TestDisconnect(t *testing.T) {
+	host, guest := pair(t, 1)
+	if err := host.Start(1); err != nil {
+		t.Fatal(err)
+	}
+	if m := next(t, guest); m.Type != MsgStart {
+		t.Fatalf("guest got %q, want start", m.Type)
+	}
+
+	// The host drops without saying goodbye.
+	host.conn.c.Close()
+
+	m := next(t, guest)
+	if m.Type != MsgDisconnect || m.Error == "" {
+		t.Errorf("guest got %+v, want a disconnect with an error", m)
+	}
+	select {
+	case _, ok := <-guest.Messages():
+		if ok {
+			t.Error("messages still open after the disconnect")
+		}
+	case <-time.After(waitTimeout):
+		t.Fatal("messages not closed after the disconnect")
+	}
+}
+
+func TestClose(t *testing.T) {
+	host, guest := pair(t, 1)
+	if err := guest.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if m := next(t, host); m.Type != MsgBye {
+		t.Errorf("host got %q, want bye", m.Type)
+	}
+	if err := guest.Close(); err != nil {
+		t.Errorf("second Close = %v", err)
+	}
+}
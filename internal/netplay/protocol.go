@@ -0,0 +1,108 @@
+// Package netplay runs versus matches between two briks instances over
+// TCP.
+//
+// Each side is authoritative for its own game. It streams the inputs that
+// drive its engine, including the garbage it takes in, and the other side
+// replays them on a mirror engine, just like a replay. Attacks are sent as
+// separate messages and the host decides who won each round.
+package netplay
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/meszmate/briks/internal/config"
+)
+
+// ProtocolVersion is bumped whenever messages change incompatibly.
+const ProtocolVersion = 2
+
+// DefaultAddr is where `briks host` listens without an address.
+const DefaultAddr = ":7777"
+
+// handshakeTimeout bounds how long the handshake may take once connected.
+const handshakeTimeout = 10 * time.Second
+
+// Message types.
+const (
+	// MsgHello opens the handshake from the joining side.
+	MsgHello = "hello"
+	// MsgWelcome accepts a hello and carries the match settings.
+	MsgWelcome = "welcome"
+	// MsgReject refuses a hello; Error says why.
+	MsgReject = "reject"
+	// MsgStart begins a round with Seed. Sent by the host.
+	MsgStart = "start"
+	// MsgInput is an action applied at Time on the sender's game clock.
+	MsgInput = "input"
+	// MsgGarbage is Lines of garbage queued at Time in the sender's game.
+	MsgGarbage = "garbage"
+	// MsgClock reports that the sender's game clock reached Time.
+	MsgClock = "clock"
+	// MsgAttack sends Lines of garbage to the receiver.
+	MsgAttack = "attack"
+	// MsgTopOut reports that the joining side topped out.
+	MsgTopOut = "topout"
+	// MsgRound ends a round with Winner (0 host, 1 guest, -1 draw). Sent
+	// by the host.
+	MsgRound = "round"
+	// MsgBye announces a graceful disconnect.
+	MsgBye = "bye"
+	// MsgDisconnect is delivered locally when the connection is lost.
+	MsgDisconnect = "disconnect"
+)
+
+// Message is one protocol message. Only the fields of its type are set.
+type Message struct {
+	Type string `json:"type"`
+
+	Version    int    `json:"version,omitempty"`
+	RNGVersion int    `json:"rng_version,omitempty"`
+	BestOf     int    `json:"best_of,omitempty"`
+	Error      string `json:"error,omitempty"`
+
+	Seed   uint64        `json:"seed,omitempty"`
+	Time   time.Duration `json:"time,omitempty"`
+	Action config.Action `json:"action,omitempty"`
+	Lines  int           `json:"lines,omitempty"`
+	Winner int           `json:"winner,omitempty"`
+}
+
+// conn exchanges newline-delimited JSON messages. Sends may come from any
+// goroutine.
+type conn struct {
+	c   net.Conn
+	dec *json.Decoder
+
+	mu  sync.Mutex
+	enc *json.Encoder
+	buf *bufio.Writer
+}
+
+func newConn(c net.Conn) *conn {
+	buf := bufio.NewWriter(c)
+	return &conn{
+		c:   c,
+		dec: json.NewDecoder(bufio.NewReader(c)),
+		enc: json.NewEncoder(buf),
+		buf: buf,
+	}
+}
+
+func (c *conn) send(m Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.enc.Encode(m); err != nil {
+		return err
+	}
+	return c.buf.Flush()
+}
+
+func (c *conn) receive() (Message, error) {
+	var m Message
+	err := c.dec.Decode(&m)
+	return m, err
+}
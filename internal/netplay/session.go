@@ -0,0 +1,208 @@
+package netplay
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/meszmate/briks/internal/config"
+	"github.com/meszmate/briks/internal/game"
+)
+
+// Session is an established connection to the opponent.
+type Session struct {
+	// Host is set on the side that accepted the connection. The host
+	// starts rounds and decides their outcome.
+	Host bool
+	// BestOf is the match length chosen by the host.
+	BestOf int
+
+	conn     *conn
+	messages chan Message
+	done     chan struct{}
+	once     sync.Once
+}
+
+// Accept waits on ln for one opponent and completes the handshake,
+// offering a best-of-N match.
+func Accept(ln net.Listener, bestOf int) (*Session, error) {
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			return nil, err
+		}
+		s, err := acceptHandshake(c, bestOf)
+		if err == nil {
+			return s, nil
+		}
+		// A bad handshake, e.g. from an old version, doesn't stop the
+		// host from waiting for someone else.
+		c.Close()
+	}
+}
+
+func acceptHandshake(c net.Conn, bestOf int) (*Session, error) {
+	cn := newConn(c)
+	c.SetDeadline(time.Now().Add(handshakeTimeout))
+	hello, err := cn.receive()
+	if err != nil {
+		return nil, err
+	}
+	if hello.Type != MsgHello {
+		return nil, fmt.Errorf("netplay: expected hello, got %q", hello.Type)
+	}
+	if reason := incompatible(hello); reason != "" {
+		cn.send(Message{Type: MsgReject, Error: reason})
+		return nil, &rejectError{reason}
+	}
+	if err := cn.send(Message{
+		Type:       MsgWelcome,
+		Version:    ProtocolVersion,
+		RNGVersion: game.RNGVersion,
+		BestOf:     bestOf,
+	}); err != nil {
+		return nil, err
+	}
+	c.SetDeadline(time.Time{})
+	return newSession(cn, true, bestOf), nil
+}
+
+// Dial connects to a host and completes the handshake.
+func Dial(addr string) (*Session, error) {
+	c, err := net.DialTimeout("tcp", addr, handshakeTimeout)
+	if err != nil {
+		return nil, err
+	}
+	cn := newConn(c)
+	c.SetDeadline(time.Now().Add(handshakeTimeout))
+	if err := cn.send(Message{
+		Type:       MsgHello,
+		Version:    ProtocolVersion,
+		RNGVersion: game.RNGVersion,
+	}); err != nil {
+		c.Close()
+		return nil, err
+	}
+	reply, err := cn.receive()
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	switch {
+	case reply.Type == MsgReject:
+		c.Close()
+		return nil, &rejectError{reply.Error}
+	case reply.Type != MsgWelcome:
+		c.Close()
+		return nil, fmt.Errorf("netplay: expected welcome, got %q", reply.Type)
+	}
+	if reason := incompatible(reply); reason != "" {
+		c.Close()
+		return nil, &rejectError{reason}
+	}
+	c.SetDeadline(time.Time{})
+	return newSession(cn, false, reply.BestOf), nil
+}
+
+// incompatible returns why the peer of a hello or welcome can't play with
+// us, or an empty string if it can.
+func incompatible(m Message) string {
+	switch {
+	case m.Version != ProtocolVersion:
+		return fmt.Sprintf("protocol version %d, want %d", m.Version, ProtocolVersion)
+	case m.RNGVersion != game.RNGVersion:
+		return fmt.Sprintf("randomizer version %d, want %d", m.RNGVersion, game.RNGVersion)
+	default:
+		return ""
+	}
+}
+
+// rejectError is a handshake refused for incompatibility.
+type rejectError struct {
+	reason string
+}
+
+func (e *rejectError) Error() string {
+	return "netplay: incompatible opponent: " + e.reason
+}
+
+func newSession(cn *conn, host bool, bestOf int) *Session {
+	s := &Session{
+		Host:     host,
+		BestOf:   bestOf,
+		conn:     cn,
+		messages: make(chan Message, 256),
+		done:     make(chan struct{}),
+	}
+	go s.read()
+	return s
+}
+
+// read delivers incoming messages until the connection ends, then a final
+// MsgDisconnect (or the peer's MsgBye) and closes the channel.
+func (s *Session) read() {
+	defer close(s.messages)
+	for {
+		m, err := s.conn.receive()
+		if err != nil {
+			m = Message{Type: MsgDisconnect, Error: err.Error()}
+		}
+		select {
+		case s.messages <- m:
+		case <-s.done:
+			return
+		}
+		if m.Type == MsgBye || m.Type == MsgDisconnect {
+			return
+		}
+	}
+}
+
+// Messages returns the incoming messages in the order they were sent.
+// The channel is closed after a MsgBye or MsgDisconnect.
+func (s *Session) Messages() <-chan Message {
+	return s.messages
+}
+
+// Send writes a message to the opponent.
+func (s *Session) Send(m Message) error {
+	return s.conn.send(m)
+}
+
+// Start begins a round. Only the host starts rounds.
+func (s *Session) Start(seed uint64) error {
+	return s.Send(Message{Type: MsgStart, Seed: seed})
+}
+
+// Input reports an action applied to the local game at time t.
+func (s *Session) Input(t time.Duration, action config.Action) error {
+	return s.Send(Message{Type: MsgInput, Time: t, Action: action})
+}
+
+// Garbage reports garbage queued in the local game at time t.
+func (s *Session) Garbage(t time.Duration, lines int) error {
+	return s.Send(Message{Type: MsgGarbage, Time: t, Lines: lines})
+}
+
+// Clock reports the local game clock.
+func (s *Session) Clock(t time.Duration) error {
+	return s.Send(Message{Type: MsgClock, Time: t})
+}
+
+// Attack sends garbage to the opponent.
+func (s *Session) Attack(lines int) error {
+	return s.Send(Message{Type: MsgAttack, Lines: lines})
+}
+
+// Close says goodbye and closes the connection. It is safe to call more
+// than once.
+func (s *Session) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.done)
+		s.Send(Message{Type: MsgBye})
+		err = s.conn.c.Close()
+	})
+	return err
+}
@@ -0,0 +1,230 @@
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/meszmate/briks/internal/config"
+	"github.com/meszmate/briks/internal/game"
+)
+
+// Version is the replay file format version.
+const Version = 1
+
+const (
+	magic     = "BRKR"
+	extension = ".brr"
+	replayDir = "replays"
+
+	// MaxReplays is how many saved replays are kept; older ones are pruned.
+	MaxReplays = 100
+)
+
+// actionCodes fixes the on-disk code of each action. Only ever append to it.
+var actionCodes = []config.Action{
+	config.ActionMoveLeft,
+	config.ActionMoveRight,
+	config.ActionSoftDrop,
+	config.ActionHardDrop,
+	config.ActionRotateCW,
+	config.ActionRotateCCW,
+	config.ActionHold,
+	config.ActionRotate180,
+}
+
+var errFormat = errors.New("not a briks replay")
+
+// Encode writes a replay in the compact binary format: a magic string, a
+// length-prefixed JSON header and the inputs as (time delta, action code)
+// varint pairs.
+func Encode(w io.Writer, r *Replay) error {
+	header, err := json.Marshal(r.Header)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(magic)
+	buf.Write(binary.AppendUvarint(nil, uint64(len(header))))
+	buf.Write(header)
+	buf.Write(binary.AppendUvarint(nil, uint64(len(r.Inputs))))
+
+	var last time.Duration
+	for _, in := range r.Inputs {
+		code := -1
+		for i, a := range actionCodes {
+			if a == in.Action {
+				code = i
+				break
+			}
+		}
+		if code < 0 {
+			return fmt.Errorf("replay: cannot encode action %q", in.Action)
+		}
+		buf.Write(binary.AppendUvarint(nil, uint64(in.Time-last)))
+		buf.Write(binary.AppendUvarint(nil, uint64(code)))
+		last = in.Time
+	}
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// Decode reads a replay written by Encode.
+func Decode(rd io.Reader) (*Replay, error) {
+	br := bufio.NewReader(rd)
+
+	m := make([]byte, len(magic))
+	if _, err := io.ReadFull(br, m); err != nil || string(m) != magic {
+		return nil, errFormat
+	}
+
+	n, err := binary.ReadUvarint(br)
+	if err != nil || n > 1<<20 {
+		return nil, errFormat
+	}
+	header := make([]byte, n)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, errFormat
+	}
+
+	r := &Replay{}
+	if err := json.Unmarshal(header, &r.Header); err != nil {
+		return nil, fmt.Errorf("replay: bad header: %w", err)
+	}
+	if r.Version > Version {
+		return nil, fmt.Errorf("replay: format version %d is newer than supported %d", r.Version, Version)
+	}
+	if r.RNGVersion != game.RNGVersion {
+		return nil, fmt.Errorf("replay: recorded with randomizer version %d, this build has %d", r.RNGVersion, game.RNGVersion)
+	}
+	if _, ok := game.RotationSystemNamed(r.Rotation); !ok {
+		return nil, fmt.Errorf("replay: unknown rotation system %q", r.Rotation)
+	}
+
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, errFormat
+	}
+	var t time.Duration
+	for i := uint64(0); i < count; i++ {
+		dt, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, errFormat
+		}
+		code, err := binary.ReadUvarint(br)
+		if err != nil || code >= uint64(len(actionCodes)) {
+			return nil, errFormat
+		}
+		t += time.Duration(dt)
+		r.Inputs = append(r.Inputs, Input{Time: t, Action: actionCodes[code]})
+	}
+
+	return r, nil
+}
+
+// Load reads a replay file.
+func Load(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Decode(f)
+}
+
+// Dir returns the directory the replays of a player's data directory,
+// such as config.Config.DataDir, are saved in.
+func Dir(dataDir string) string {
+	return filepath.Join(dataDir, replayDir)
+}
+
+// Save writes a replay to the replay directory of dataDir and prunes old
+// ones. Returns the path of the new file.
+func Save(dataDir string, r *Replay) (string, error) {
+	dir := Dir(dataDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, r); err != nil {
+		return "", err
+	}
+	// The timestamp keeps the names in chronological order; the random
+	// part keeps games finished in the same millisecond apart.
+	f, err := os.CreateTemp(dir, r.Date.Format("20060102-150405.000")+"-*"+extension)
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(buf.Bytes())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+
+	prune(dir)
+	return f.Name(), nil
+}
+
+// Entry is a saved replay as shown in listings.
+type Entry struct {
+	Path   string
+	Header Header
+}
+
+// List returns the replays saved in the replay directory of dataDir,
+// newest first. Unreadable files are skipped.
+func List(dataDir string) []Entry {
+	dir := Dir(dataDir)
+	names := replayFiles(dir)
+
+	var entries []Entry
+	for i := len(names) - 1; i >= 0; i-- {
+		path := filepath.Join(dir, names[i])
+		r, err := Load(path)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, Entry{Path: path, Header: r.Header})
+	}
+	return entries
+}
+
+// replayFiles returns the replay file names in dir, oldest first. The
+// timestamped names sort chronologically.
+func replayFiles(dir string) []string {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, f := range files {
+		if !f.IsDir() && strings.HasSuffix(f.Name(), extension) {
+			names = append(names, f.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func prune(dir string) {
+	names := replayFiles(dir)
+	for len(names) > MaxReplays {
+		_ = os.Remove(filepath.Join(dir, names[0]))
+		names = names[1:]
+	}
+}
@@ -0,0 +1,28 @@
+package replay
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/meszmate/briks/internal/config"
+	"github.com/meszmate/briks/internal/game"
+)
+
+func TestDecodeRNGVersion(t *testing.T) {
+	rec := NewRecorder(game.NewEngine(game.MarathonOptions(1, 5, 3)), config.DefaultConfig())
+	rec.Do(config.ActionMoveLeft)
+	rec.Do(config.ActionHardDrop)
+	r := rec.Finish()
+
+	for _, v := range []int{game.RNGVersion, game.RNGVersion + 1, 0} {
+		r.Header.RNGVersion = v
+		var buf bytes.Buffer
+		if err := Encode(&buf, r); err != nil {
+			t.Fatal(err)
+		}
+		_, err := Decode(&buf)
+		if ok := v == game.RNGVersion; (err == nil) != ok {
+			t.Errorf("randomizer version %d: Decode error %v", v, err)
+		}
+	}
+}
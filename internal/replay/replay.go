@@ -0,0 +1,202 @@
+package replay
+
+import (
+	"time"
+
+	"github.com/meszmate/briks/internal/config"
+	"github.com/meszmate/briks/internal/game"
+)
+
+// Replay is a recorded game: everything needed to rebuild the engine plus
+// the timestamped inputs that were applied to it.
+type Replay struct {
+	Header
+	Inputs []Input
+}
+
+// Header describes how the recorded game was set up and how it ended.
+type Header struct {
+	Version          int           `json:"version"`
+	RNGVersion       int           `json:"rng_version"`
+	Seed             uint64        `json:"seed"`
+	Mode             game.Mode     `json:"mode"`
+	LineGoal         int           `json:"line_goal,omitempty"`
+	TimeLimit        time.Duration `json:"time_limit,omitempty"`
+	GarbageMessiness float64       `json:"garbage_messiness,omitempty"`
+	GarbageGoal      int           `json:"garbage_goal,omitempty"`
+	Width            int           `json:"width,omitempty"` // zero in replays of the standard board from before sizes
+	Height           int           `json:"height,omitempty"`
+	Rotation         string        `json:"rotation,omitempty"` // rotation system name; empty is SRS
+	StartLevel       int           `json:"start_level"`
+	PreviewCount     int           `json:"preview_count"`
+	DAS              int           `json:"das"`
+	ARR              int           `json:"arr"`
+	Date             time.Time     `json:"date"`
+
+	// Final state, used for listings and to verify playback.
+	Duration time.Duration `json:"duration"`
+	Score    int           `json:"score"`
+	Lines    int           `json:"lines"`
+	Pieces   int           `json:"pieces"`
+}
+
+// Input is a single action applied at a given game clock value.
+type Input struct {
+	Time   time.Duration
+	Action config.Action
+}
+
+// Options returns the options the recorded game was created with.
+func (h Header) Options() game.Options {
+	rs, _ := game.RotationSystemNamed(h.Rotation)
+	return game.Options{
+		Mode:         h.Mode,
+		StartLevel:   h.StartLevel,
+		PreviewCount: h.PreviewCount,
+		Seed:         h.Seed,
+		LineGoal:     h.LineGoal,
+		TimeLimit:    h.TimeLimit,
+
+		GarbageMessiness: h.GarbageMessiness,
+		GarbageGoal:      h.GarbageGoal,
+		Width:            h.Width,
+		Height:           h.Height,
+		Rotation:         rs,
+	}
+}
+
+// NewEngine creates an engine in the same initial state as the recorded game.
+func (r *Replay) NewEngine() *game.Engine {
+	return game.NewEngine(r.Options())
+}
+
+// Apply performs a game action on the engine. Actions that don't affect the
+// engine, such as pause, are ignored. Returns whether the action did anything.
+func Apply(e *game.Engine, action config.Action) bool {
+	switch action {
+	case config.ActionMoveLeft:
+		return e.MoveLeft()
+	case config.ActionMoveRight:
+		return e.MoveRight()
+	case config.ActionSoftDrop:
+		return e.SoftDrop()
+	case config.ActionHardDrop:
+		e.HardDrop()
+		return true
+	case config.ActionRotateCW:
+		return e.RotateCW()
+	case config.ActionRotateCCW:
+		return e.RotateCCW()
+	case config.ActionRotate180:
+		return e.Rotate180()
+	case config.ActionHold:
+		return e.Hold()
+	}
+	return false
+}
+
+// Recorder applies actions to a live engine and records them.
+type Recorder struct {
+	engine *game.Engine
+	replay *Replay
+}
+
+// NewRecorder starts recording a game that has just been created.
+func NewRecorder(e *game.Engine, cfg *config.Config) *Recorder {
+	opts := e.Options()
+	return &Recorder{
+		engine: e,
+		replay: &Replay{Header: Header{
+			Version:          Version,
+			RNGVersion:       game.RNGVersion,
+			Seed:             opts.Seed,
+			Mode:             opts.Mode,
+			LineGoal:         opts.LineGoal,
+			TimeLimit:        opts.TimeLimit,
+			GarbageMessiness: opts.GarbageMessiness,
+			GarbageGoal:      opts.GarbageGoal,
+			Width:            opts.Width,
+			Height:           opts.Height,
+			Rotation:         e.Board.System.Name(),
+			StartLevel:       opts.StartLevel,
+			PreviewCount:     opts.PreviewCount,
+			DAS:              cfg.DAS,
+			ARR:              cfg.ARR,
+			Date:             time.Now(),
+		}},
+	}
+}
+
+// ResumeRecorder goes on recording r on e, an engine restored to where
+// the recording left off.
+func ResumeRecorder(e *game.Engine, r *Replay) *Recorder {
+	return &Recorder{engine: e, replay: r}
+}
+
+// Do applies an action to the engine at the current game clock and
+// records it. Actions that had no effect are not recorded.
+func (r *Recorder) Do(action config.Action) bool {
+	in := Input{Time: r.engine.Clock, Action: action}
+	state := r.engine.State
+	ok := Apply(r.engine, action)
+	if ok || r.engine.State != state {
+		r.replay.Inputs = append(r.replay.Inputs, in)
+	}
+	return ok
+}
+
+// Finish stamps the final game state and returns the recording.
+func (r *Recorder) Finish() *Replay {
+	r.replay.Duration = r.engine.Clock
+	r.replay.Score = r.engine.Scorer.Score
+	r.replay.Lines = r.engine.Scorer.Lines
+	r.replay.Pieces = r.engine.PiecesPlaced
+	return r.replay
+}
+
+// Player re-simulates a replay on its own engine.
+type Player struct {
+	Replay *Replay
+	Engine *game.Engine
+	next   int
+}
+
+// NewPlayer creates a player positioned at the start of the replay.
+func NewPlayer(r *Replay) *Player {
+	return &Player{Replay: r, Engine: r.NewEngine()}
+}
+
+// SeekForward plays the replay up to the given game clock value, or to
+// its end, whichever comes first.
+func (p *Player) SeekForward(t time.Duration) {
+	t = min(t, p.Replay.Duration)
+	for p.next < len(p.Replay.Inputs) && p.Replay.Inputs[p.next].Time <= t {
+		in := p.Replay.Inputs[p.next]
+		p.Engine.Advance(in.Time - p.Engine.Clock)
+		Apply(p.Engine, in.Action)
+		p.next++
+	}
+	if t > p.Engine.Clock {
+		p.Engine.Advance(t - p.Engine.Clock)
+	}
+}
+
+// Done reports whether the whole replay has been played.
+func (p *Player) Done() bool {
+	return p.next >= len(p.Replay.Inputs) && p.Engine.Clock >= p.Replay.Duration ||
+		p.Engine.Over()
+}
+
+// Verified reports whether the simulated game ended with the recorded score.
+func (p *Player) Verified() bool {
+	return p.Engine.Scorer.Score == p.Replay.Score &&
+		p.Engine.Scorer.Lines == p.Replay.Lines &&
+		p.Engine.PiecesPlaced == p.Replay.Pieces
+}
+
+// Simulate plays a whole replay headlessly and returns the final engine.
+func Simulate(r *Replay) *game.Engine {
+	p := NewPlayer(r)
+	p.SeekForward(r.Duration)
+	return p.Engine
+}
@@ -0,0 +1,109 @@
+package replay_test
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/meszmate/briks/internal/ai"
+	"github.com/meszmate/briks/internal/config"
+	"github.com/meszmate/briks/internal/game"
+	"github.com/meszmate/briks/internal/replay"
+)
+
+// noise are the inputs mixed into the bot's placements, so the recording
+// has every kind of action in it.
+var noise = []config.Action{
+	config.ActionMoveLeft,
+	config.ActionMoveRight,
+	config.ActionRotateCW,
+	config.ActionRotateCCW,
+	config.ActionRotate180,
+	config.ActionSoftDrop,
+	config.ActionHold,
+}
+
+// record plays a game of opts with the built-in AI, stray inputs and time
+// passing between inputs, and returns its recording and final engine.
+func record(t *testing.T, opts game.Options, pieces int) (*replay.Replay, *game.Engine) {
+	t.Helper()
+	rng := rand.New(rand.NewPCG(opts.Seed, 1))
+	e := game.NewEngine(opts)
+	rec := replay.NewRecorder(e, config.DefaultConfig())
+	bot := ai.New(nil)
+	for e.State == game.StatePlaying && e.PiecesPlaced < pieces {
+		for range rng.IntN(3) {
+			rec.Do(noise[rng.IntN(len(noise))])
+			e.Advance(time.Duration(rng.IntN(200)) * time.Millisecond)
+		}
+		if e.State != game.StatePlaying {
+			break
+		}
+		p, ok := bot.Best(ai.StateOf(e))
+		if !ok {
+			break
+		}
+		for _, a := range p.Actions() {
+			// Now and then gravity pulls the piece down mid-placement.
+			e.Advance(time.Duration(rng.IntN(120)) * time.Millisecond)
+			rec.Do(a)
+		}
+	}
+	// Let gravity and lock delay run out the last piece.
+	e.Advance(3 * time.Second)
+	return rec.Finish(), e
+}
+
+func TestSimulateReproducesRecording(t *testing.T) {
+	tests := []struct {
+		name string
+		opts game.Options
+	}{
+		{"marathon", game.MarathonOptions(5, 5, 11)},
+		{"cheese", game.CheeseOptions(18, 3, 12)},
+		{"srs+", func() game.Options {
+			o := game.MarathonOptions(1, 5, 13)
+			o.Rotation = game.SRSPlus
+			return o
+		}()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, e := record(t, tt.opts, 60)
+			if e.Scorer.Lines == 0 {
+				t.Fatal("the recorded game cleared no lines")
+			}
+			kinds := map[config.Action]bool{}
+			for _, in := range r.Inputs {
+				kinds[in.Action] = true
+			}
+			for _, a := range noise {
+				if !kinds[a] {
+					t.Errorf("no %s in the recording", a)
+				}
+			}
+
+			var buf bytes.Buffer
+			if err := replay.Encode(&buf, r); err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := replay.Decode(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := replay.Simulate(decoded)
+			if got.Scorer.Score != r.Score || got.Scorer.Lines != r.Lines || got.PiecesPlaced != r.Pieces {
+				t.Errorf("simulated score %d, lines %d, pieces %d; recorded %d, %d, %d",
+					got.Scorer.Score, got.Scorer.Lines, got.PiecesPlaced, r.Score, r.Lines, r.Pieces)
+			}
+			if !got.Board.Equal(e.Board) {
+				t.Error("simulated board differs from the recorded game's")
+			}
+			if got.State != e.State {
+				t.Errorf("simulated state %v, recorded %v", got.State, e.State)
+			}
+		})
+	}
+}
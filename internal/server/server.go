@@ -0,0 +1,154 @@
+// Package server serves briks over SSH, so anyone with an SSH client can
+// play on a shared machine without installing anything.
+//
+// Every player gets the full game with their own settings and key
+// bindings, kept per public key. Players without a key play as guests,
+// whose data lasts only as long as their session, since anyone can log
+// in with any user name. The leaderboards are shared by everyone on the
+// server.
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/charmbracelet/wish/activeterm"
+	bm "github.com/charmbracelet/wish/bubbletea"
+	"github.com/charmbracelet/wish/logging"
+	"github.com/meszmate/briks/internal/config"
+	"github.com/meszmate/briks/internal/tui"
+	"github.com/muesli/termenv"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// DefaultAddr is where `briks serve` listens without an address.
+const DefaultAddr = ":2222"
+
+const (
+	hostKeyFile = "ssh_host_ed25519"
+	usersDir    = "users"
+	guestsDir   = "guests"
+)
+
+// shutdownTimeout bounds how long open sessions are waited for on exit.
+const shutdownTimeout = 5 * time.Second
+
+// Server serves the game over SSH from a data directory holding the host
+// key, the shared leaderboards and each player's own data.
+type Server struct {
+	dir    string
+	scores *config.HighScores
+	times  *config.BestTimes
+}
+
+// New creates a server that keeps its data in dir.
+func New(dir string) *Server {
+	return &Server{
+		dir:    dir,
+		scores: config.LoadHighScoresFrom(dir),
+		times:  config.LoadBestTimesFrom(dir),
+	}
+}
+
+// ListenAndServe serves on addr until ctx is done, then gives open
+// sessions a moment to finish.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	// Guest data left behind by a server that didn't shut down cleanly
+	// belongs to no one.
+	if err := os.RemoveAll(filepath.Join(s.dir, guestsDir)); err != nil {
+		return err
+	}
+	srv, err := wish.NewServer(
+		wish.WithAddress(addr),
+		wish.WithHostKeyPath(filepath.Join(s.dir, hostKeyFile)),
+		// Anyone may play; keys only tell players apart.
+		wish.WithPublicKeyAuth(func(ssh.Context, ssh.PublicKey) bool { return true }),
+		wish.WithKeyboardInteractiveAuth(func(ssh.Context, gossh.KeyboardInteractiveChallenge) bool { return true }),
+		wish.WithMiddleware(
+			resetKeyboard,
+			bm.Middleware(s.session),
+			activeterm.Middleware(),
+			logging.Middleware(),
+		),
+	)
+	if err != nil {
+		return err
+	}
+
+	// The styles render through the process-wide renderer, which can't
+	// know each client's terminal, so assume a modern one.
+	lipgloss.SetColorProfile(termenv.TrueColor)
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// session creates the game for a connecting player.
+func (s *Server) session(sess ssh.Session) (tea.Model, []tea.ProgramOption) {
+	dir, err := s.playerDir(sess)
+	if err != nil {
+		wish.Fatalln(sess, "briks: "+err.Error())
+		return nil, nil
+	}
+	app := tui.NewApp(config.LoadFrom(dir), config.LoadKeyBindingsFrom(dir), s.scores, s.times)
+	app.SetPlayer(sess.User())
+	app.SetOutput(sess)
+	return app, []tea.ProgramOption{tea.WithAltScreen()}
+}
+
+// resetKeyboard restores the client's keyboard mode once the game ends.
+func resetKeyboard(next ssh.Handler) ssh.Handler {
+	return func(sess ssh.Session) {
+		next(sess)
+		tui.ResetKeyboard(sess)
+	}
+}
+
+// playerDir returns the directory of a player's own data. Players with a
+// public key keep theirs by a hash of the key. A guest, who logged in
+// without a key, gets a new directory that is removed when the session
+// ends: a user name proves nothing, so keying data by it would let anyone
+// take over another player's.
+func (s *Server) playerDir(sess ssh.Session) (string, error) {
+	if key := sess.PublicKey(); key != nil {
+		sum := sha256.Sum256(key.Marshal())
+		return filepath.Join(s.dir, usersDir, "key-"+hex.EncodeToString(sum[:])), nil
+	}
+	guests := filepath.Join(s.dir, guestsDir)
+	if err := os.MkdirAll(guests, 0755); err != nil {
+		return "", err
+	}
+	dir, err := os.MkdirTemp(guests, "guest-")
+	if err != nil {
+		return "", err
+	}
+	go func() {
+		<-sess.Context().Done()
+		_ = os.RemoveAll(dir)
+	}()
+	return dir, nil
+}
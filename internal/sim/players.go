@@ -0,0 +1,46 @@
+package sim
+
+import (
+	"github.com/meszmate/briks/internal/ai"
+	"github.com/meszmate/briks/internal/config"
+	"github.com/meszmate/briks/internal/game"
+	"github.com/meszmate/briks/internal/tbp"
+)
+
+// aiPlayer plays with the built-in AI.
+type aiPlayer struct {
+	bot *ai.Bot
+}
+
+// AI returns a player using the built-in AI. It can play many games at
+// once.
+func AI(bot *ai.Bot) Player {
+	return aiPlayer{bot: bot}
+}
+
+func (p aiPlayer) Move(e *game.Engine) ([]config.Action, error) {
+	best, ok := p.bot.Best(ai.StateOf(e))
+	if !ok {
+		return nil, nil
+	}
+	return best.Actions(), nil
+}
+
+// tbpPlayer plays one game with a TBP bot.
+type tbpPlayer struct {
+	driver *tbp.Driver
+}
+
+// TBP returns a player for one game of a TBP bot. A bot plays one game at
+// a time.
+func TBP(c *tbp.Client) Player {
+	return tbpPlayer{driver: tbp.NewDriver(c)}
+}
+
+func (p tbpPlayer) Move(e *game.Engine) ([]config.Action, error) {
+	moves, err := p.driver.Suggest(e)()
+	if err != nil {
+		return nil, err
+	}
+	return p.driver.Play(e, moves)
+}
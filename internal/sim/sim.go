@@ -0,0 +1,116 @@
+// Package sim plays games with a bot and no interface, as fast as it can,
+// and sums up how the bot did. It is for comparing bots and heuristics
+// and for catching engine slowdowns.
+package sim
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/meszmate/briks/internal/config"
+	"github.com/meszmate/briks/internal/game"
+	"github.com/meszmate/briks/internal/replay"
+)
+
+// Player chooses the actions that place the current piece of a game.
+type Player interface {
+	Move(e *game.Engine) ([]config.Action, error)
+}
+
+// Options configures a simulation.
+type Options struct {
+	// Games is how many games to play; game i uses seed Seed+i.
+	Games int
+	Seed  uint64
+	// MaxPieces ends a game that gets this far without topping out.
+	MaxPieces int
+	// Workers is how many games are played at once.
+	Workers int
+}
+
+// Result is how one game went.
+type Result struct {
+	Seed      uint64
+	Score     int
+	Lines     int
+	Pieces    int
+	ToppedOut bool
+	// Clears counts the line clears and T-Spins by type.
+	Clears   [game.ClearTSpinMiniDouble + 1]int
+	Duration time.Duration
+}
+
+// Run plays the games of opts, each with a player from newPlayer, and
+// returns their results in seed order.
+func Run(opts Options, newPlayer func() (Player, error)) ([]Result, error) {
+	results := make([]Result, opts.Games)
+	workers := min(max(opts.Workers, 1), max(opts.Games, 1))
+	next := make(chan int)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if errs[w] != nil {
+					continue
+				}
+				p, err := newPlayer()
+				if err != nil {
+					errs[w] = err
+					continue
+				}
+				seed := opts.Seed + uint64(i)
+				results[i], errs[w] = Play(game.MarathonOptions(1, 5, seed), p, opts.MaxPieces)
+			}
+		}()
+	}
+	for i := range opts.Games {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results, errors.Join(errs...)
+}
+
+// Play plays one game with p until it tops out or maxPieces are placed.
+func Play(opts game.Options, p Player, maxPieces int) (Result, error) {
+	start := time.Now()
+	e := game.NewEngine(opts)
+	r := Result{Seed: opts.Seed}
+	e.Subscribe(func(ev game.Event) {
+		switch ev := ev.(type) {
+		case game.LinesCleared:
+			r.Clears[ev.Type]++
+		case game.TSpin:
+			if ev.Lines == 0 {
+				r.Clears[ev.Type]++
+			}
+		}
+	})
+	for e.State == game.StatePlaying && e.PiecesPlaced < maxPieces {
+		actions, err := p.Move(e)
+		if err != nil {
+			return r, err
+		}
+		placed := e.PiecesPlaced
+		for _, a := range actions {
+			replay.Apply(e, a)
+			if e.PiecesPlaced > placed {
+				break
+			}
+		}
+		// A player that didn't drop its piece has it dropped where it is.
+		if e.PiecesPlaced == placed && e.State == game.StatePlaying {
+			e.HardDrop()
+		}
+	}
+	r.Score = e.Scorer.Score
+	r.Lines = e.Scorer.Lines
+	r.Pieces = e.PiecesPlaced
+	r.ToppedOut = e.State == game.StateGameOver
+	r.Duration = time.Since(start)
+	return r, nil
+}
@@ -0,0 +1,98 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/meszmate/briks/internal/ai"
+	"github.com/meszmate/briks/internal/game"
+)
+
+func newAI() (Player, error) {
+	return AI(ai.New(nil)), nil
+}
+
+// withoutTimes returns results with their durations cleared, which are
+// all that differ between runs of the same games.
+func withoutTimes(results []Result) []Result {
+	out := make([]Result, len(results))
+	for i, r := range results {
+		r.Duration = 0
+		out[i] = r
+	}
+	return out
+}
+
+func TestRunWorkers(t *testing.T) {
+	opts := Options{Games: 4, Seed: 100, MaxPieces: 20, Workers: 1}
+	serial, err := Run(opts, newAI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Workers = 4
+	parallel, err := Run(opts, newAI)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, r := range parallel {
+		if r.Seed != opts.Seed+uint64(i) {
+			t.Errorf("result %d has seed %d, want %d", i, r.Seed, opts.Seed+uint64(i))
+		}
+		if r.Pieces == 0 {
+			t.Errorf("game %d placed no pieces", i)
+		}
+	}
+	serial, parallel = withoutTimes(serial), withoutTimes(parallel)
+	for i := range serial {
+		if serial[i] != parallel[i] {
+			t.Errorf("game %d: %+v with one worker, %+v with four", i, serial[i], parallel[i])
+		}
+	}
+}
+
+func TestClearsMatchLines(t *testing.T) {
+	lines := map[game.LineClearType]int{
+		game.ClearSingle:          1,
+		game.ClearDouble:          2,
+		game.ClearTriple:          3,
+		game.ClearTetris:          4,
+		game.ClearTSpinSingle:     1,
+		game.ClearTSpinDouble:     2,
+		game.ClearTSpinTriple:     3,
+		game.ClearTSpinMiniSingle: 1,
+		game.ClearTSpinMiniDouble: 2,
+	}
+	results, err := Run(Options{Games: 4, Seed: 7, MaxPieces: 60, Workers: 4}, newAI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, r := range results {
+		counted := 0
+		for typ, n := range r.Clears {
+			counted += n * lines[game.LineClearType(typ)]
+		}
+		if counted != r.Lines {
+			t.Errorf("seed %d: clears %v add up to %d lines, scorer has %d", r.Seed, r.Clears, counted, r.Lines)
+		}
+		total += r.Lines
+	}
+	if total == 0 {
+		t.Error("no lines cleared")
+	}
+}
+
+// BenchmarkPlay plays the same game with the built-in AI each time, so
+// that changes in engine or bot speed show up as changes in ns/op.
+func BenchmarkPlay(b *testing.B) {
+	p := AI(ai.New(nil))
+	pieces := 0
+	for b.Loop() {
+		r, err := Play(game.MarathonOptions(1, 5, 42), p, 50)
+		if err != nil {
+			b.Fatal(err)
+		}
+		pieces += r.Pieces
+	}
+	b.ReportMetric(float64(pieces)/b.Elapsed().Seconds(), "pieces/s")
+}
@@ -0,0 +1,125 @@
+package sim
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/meszmate/briks/internal/game"
+)
+
+// Stat sums up one number across games.
+type Stat struct {
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	Min    int     `json:"min"`
+	Max    int     `json:"max"`
+}
+
+// statOf returns the Stat of the values.
+func statOf(values []int) Stat {
+	if len(values) == 0 {
+		return Stat{}
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	sum := 0
+	for _, v := range sorted {
+		sum += v
+	}
+	n := len(sorted)
+	median := float64(sorted[n/2])
+	if n%2 == 0 {
+		median = float64(sorted[n/2-1]+sorted[n/2]) / 2
+	}
+	return Stat{
+		Mean:   float64(sum) / float64(n),
+		Median: median,
+		Min:    sorted[0],
+		Max:    sorted[n-1],
+	}
+}
+
+// Summary is how a bot did over a number of games.
+type Summary struct {
+	Bot    string `json:"bot"`
+	Games  int    `json:"games"`
+	Seed   uint64 `json:"seed"`
+	Score  Stat   `json:"score"`
+	Lines  Stat   `json:"lines"`
+	Pieces Stat   `json:"pieces"`
+	// TopoutRate is the share of games that ended by topping out rather
+	// than by reaching the piece limit.
+	TopoutRate float64 `json:"topout_rate"`
+	// Clears counts the line clears and T-Spins of all games, by name.
+	Clears map[string]int `json:"clears"`
+	// PiecesPerSecond is the pieces placed per second each game took to
+	// play; it measures the bot and the engine together.
+	PiecesPerSecond float64 `json:"pieces_per_second"`
+	// Elapsed is the wall clock time of the run.
+	Elapsed time.Duration `json:"elapsed_ns"`
+}
+
+// Summarize sums up the results of the games of a run started at seed.
+func Summarize(bot string, seed uint64, results []Result, elapsed time.Duration) Summary {
+	s := Summary{
+		Bot:     bot,
+		Games:   len(results),
+		Seed:    seed,
+		Clears:  make(map[string]int),
+		Elapsed: elapsed,
+	}
+	scores := make([]int, len(results))
+	lines := make([]int, len(results))
+	pieces := make([]int, len(results))
+	toppedOut, totalPieces := 0, 0
+	var busy time.Duration
+	for i, r := range results {
+		scores[i], lines[i], pieces[i] = r.Score, r.Lines, r.Pieces
+		if r.ToppedOut {
+			toppedOut++
+		}
+		for t, n := range r.Clears {
+			if name := game.ClearName(game.LineClearType(t)); name != "" && n > 0 {
+				s.Clears[name] += n
+			}
+		}
+		totalPieces += r.Pieces
+		busy += r.Duration
+	}
+	s.Score = statOf(scores)
+	s.Lines = statOf(lines)
+	s.Pieces = statOf(pieces)
+	if len(results) > 0 {
+		s.TopoutRate = float64(toppedOut) / float64(len(results))
+	}
+	if busy > 0 {
+		s.PiecesPerSecond = float64(totalPieces) / busy.Seconds()
+	}
+	return s
+}
+
+// WriteText writes the summary as a plain text report.
+func (s Summary) WriteText(w io.Writer) error {
+	stat := func(name string, st Stat) string {
+		return fmt.Sprintf("%-8s mean %10.1f  median %10.1f  min %8d  max %8d\n", name, st.Mean, st.Median, st.Min, st.Max)
+	}
+	text := fmt.Sprintf("bot %s, %d games from seed %d in %s\n\n", s.Bot, s.Games, s.Seed, s.Elapsed.Round(time.Millisecond))
+	text += stat("score", s.Score)
+	text += stat("lines", s.Lines)
+	text += stat("pieces", s.Pieces)
+	text += fmt.Sprintf("\ntop outs  %.1f%%\nspeed     %.0f pieces/s\n", 100*s.TopoutRate, s.PiecesPerSecond)
+	if len(s.Clears) > 0 {
+		text += "\nclears\n"
+		// List the clears in the engine's order rather than the map's.
+		for t := game.ClearSingle; t <= game.ClearTSpinMiniDouble; t++ {
+			name := game.ClearName(t)
+			if n := s.Clears[name]; n > 0 {
+				text += fmt.Sprintf("  %-14s %8d  %6.2f/game\n", name, n, float64(n)/float64(max(s.Games, 1)))
+			}
+		}
+	}
+	_, err := io.WriteString(w, text)
+	return err
+}
@@ -0,0 +1,298 @@
+// Package spectate broadcasts a game to spectators over TCP.
+//
+// A spectator first gets a snapshot of the whole game, then updates that
+// carry only the board rows that changed along with the rest of the
+// display state. Frames are newline-delimited JSON.
+package spectate
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/meszmate/briks/internal/game"
+)
+
+// ProtocolVersion is bumped whenever frames change incompatibly.
+const ProtocolVersion = 1
+
+// DefaultAddr is where a game broadcasts without an address.
+const DefaultAddr = ":7778"
+
+// MaxSpectators bounds how many spectators one game serves at a time.
+const MaxSpectators = 8
+
+const (
+	// queueSize is how many frames may wait for a slow spectator. One
+	// that falls further behind skips ahead to a fresh snapshot.
+	queueSize = 64
+	// writeTimeout drops spectators that stop reading altogether.
+	writeTimeout = 5 * time.Second
+)
+
+// Frame is one message to a spectator. A snapshot carries the complete
+// state; an update carries the state without its board, plus the board
+// rows that changed.
+type Frame struct {
+	Snapshot bool                     `json:"snapshot,omitempty"`
+	Version  int                      `json:"version,omitempty"`
+	State    *game.DisplayState       `json:"state,omitempty"`
+	Rows     map[int][]game.CellColor `json:"rows,omitempty"`
+	Error    string                   `json:"error,omitempty"`
+}
+
+// snapshot returns the frame that brings a spectator up to s.
+func snapshot(s *game.DisplayState) Frame {
+	return Frame{Snapshot: true, Version: ProtocolVersion, State: s}
+}
+
+// diff returns the frame that takes a spectator from prev to cur, and
+// false if nothing changed.
+func diff(prev, cur *game.DisplayState) (Frame, bool) {
+	rows := make(map[int][]game.CellColor)
+	for r, row := range cur.Board {
+		if r >= len(prev.Board) || !slices.Equal(row, prev.Board[r]) {
+			rows[r] = row
+		}
+	}
+	a, b := *prev, *cur
+	a.Board, b.Board = nil, nil
+	if len(rows) == 0 && reflect.DeepEqual(a, b) {
+		return Frame{}, false
+	}
+	return Frame{State: &b, Rows: rows}, true
+}
+
+// Broadcaster serves a game to the spectators that connect to it. Its
+// methods are safe for concurrent use.
+type Broadcaster struct {
+	ln net.Listener
+
+	mu         sync.Mutex
+	last       *game.DisplayState
+	spectators map[*spectator]struct{}
+}
+
+type spectator struct {
+	conn   net.Conn
+	frames chan Frame
+	// stale is set when frames had to be dropped; the spectator needs a
+	// snapshot before any further update.
+	stale bool
+}
+
+// Listen starts accepting spectators on addr.
+func Listen(addr string) (*Broadcaster, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	b := &Broadcaster{ln: ln, spectators: make(map[*spectator]struct{})}
+	go b.accept()
+	return b, nil
+}
+
+// Addr returns the address spectators connect to.
+func (b *Broadcaster) Addr() net.Addr {
+	return b.ln.Addr()
+}
+
+// Spectators returns the number of connected spectators.
+func (b *Broadcaster) Spectators() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.spectators)
+}
+
+// Publish sends the current state of the game to every spectator. It
+// never blocks on slow spectators.
+func (b *Broadcaster) Publish(s game.DisplayState) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var update Frame
+	if b.last == nil {
+		update = snapshot(&s)
+	} else {
+		var changed bool
+		if update, changed = diff(b.last, &s); !changed {
+			return
+		}
+	}
+	b.last = &s
+
+	for sp := range b.spectators {
+		f := update
+		if sp.stale {
+			f = snapshot(&s)
+		}
+		select {
+		case sp.frames <- f:
+			sp.stale = false
+		default:
+			sp.stale = true
+		}
+	}
+}
+
+// Close stops accepting spectators and disconnects the connected ones.
+func (b *Broadcaster) Close() error {
+	err := b.ln.Close()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sp := range b.spectators {
+		b.drop(sp)
+	}
+	return err
+}
+
+func (b *Broadcaster) accept() {
+	for {
+		c, err := b.ln.Accept()
+		if err != nil {
+			return
+		}
+		b.add(c)
+	}
+}
+
+func (b *Broadcaster) add(c net.Conn) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.spectators) >= MaxSpectators {
+		go reject(c, fmt.Sprintf("the game already has %d spectators", MaxSpectators))
+		return
+	}
+	sp := &spectator{conn: c, frames: make(chan Frame, queueSize)}
+	if b.last != nil {
+		// Late joiners start from the game as it is now.
+		sp.frames <- snapshot(b.last)
+	}
+	b.spectators[sp] = struct{}{}
+	go b.write(sp)
+	go b.watchClose(sp)
+}
+
+// write sends queued frames to a spectator until it is dropped.
+func (b *Broadcaster) write(sp *spectator) {
+	w := bufio.NewWriter(sp.conn)
+	enc := json.NewEncoder(w)
+	for f := range sp.frames {
+		sp.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if err := enc.Encode(f); err != nil {
+			break
+		}
+		// Batch whatever else is already queued into one write.
+		if len(sp.frames) > 0 {
+			continue
+		}
+		if err := w.Flush(); err != nil {
+			break
+		}
+		b.catchUp(sp)
+	}
+	b.mu.Lock()
+	b.drop(sp)
+	b.mu.Unlock()
+}
+
+// catchUp queues a snapshot for a spectator that had frames dropped, now
+// that it has caught up with the rest, so it doesn't have to wait for the
+// game to change again.
+func (b *Broadcaster) catchUp(sp *spectator) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.spectators[sp]; !ok || !sp.stale {
+		return
+	}
+	select {
+	case sp.frames <- snapshot(b.last):
+		sp.stale = false
+	default:
+	}
+}
+
+// watchClose drops a spectator as soon as it hangs up. Spectators never
+// send anything.
+func (b *Broadcaster) watchClose(sp *spectator) {
+	_, _ = io.Copy(io.Discard, sp.conn)
+	b.mu.Lock()
+	b.drop(sp)
+	b.mu.Unlock()
+}
+
+// drop disconnects a spectator. b.mu must be held.
+func (b *Broadcaster) drop(sp *spectator) {
+	if _, ok := b.spectators[sp]; !ok {
+		return
+	}
+	delete(b.spectators, sp)
+	close(sp.frames)
+	sp.conn.Close()
+}
+
+func reject(c net.Conn, reason string) {
+	defer c.Close()
+	c.SetWriteDeadline(time.Now().Add(writeTimeout))
+	_ = json.NewEncoder(c).Encode(Frame{Error: reason})
+}
+
+// Watcher follows a broadcast game.
+type Watcher struct {
+	conn  net.Conn
+	dec   *json.Decoder
+	board [][]game.CellColor
+}
+
+// Dial connects to a broadcasting game.
+func Dial(addr string) (*Watcher, error) {
+	c, err := net.DialTimeout("tcp", addr, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &Watcher{conn: c, dec: json.NewDecoder(bufio.NewReader(c))}, nil
+}
+
+// Next waits for the next frame and returns the game as it now stands.
+func (w *Watcher) Next() (game.DisplayState, error) {
+	var f Frame
+	if err := w.dec.Decode(&f); err != nil {
+		return game.DisplayState{}, err
+	}
+	switch {
+	case f.Error != "":
+		return game.DisplayState{}, errors.New(f.Error)
+	case f.State == nil:
+		return game.DisplayState{}, errors.New("spectate: frame without a state")
+	case f.Snapshot:
+		if f.Version != ProtocolVersion {
+			return game.DisplayState{}, fmt.Errorf("spectate: protocol version %d, want %d", f.Version, ProtocolVersion)
+		}
+		w.board = f.State.Board
+	case w.board == nil:
+		return game.DisplayState{}, errors.New("spectate: update before snapshot")
+	default:
+		w.board = slices.Clone(w.board)
+		for r, row := range f.Rows {
+			if r >= 0 && r < len(w.board) {
+				w.board[r] = row
+			}
+		}
+	}
+	s := *f.State
+	s.Board = w.board
+	return s, nil
+}
+
+// Close disconnects from the game.
+func (w *Watcher) Close() error {
+	return w.conn.Close()
+}
@@ -0,0 +1,211 @@
+package spectate
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meszmate/briks/internal/game"
+)
+
+// waitTimeout bounds every wait in these tests.
+const waitTimeout = 5 * time.Second
+
+// listen starts a broadcaster on a free loopback port.
+func listen(t *testing.T) *Broadcaster {
+	t.Helper()
+	b, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Close() })
+	return b
+}
+
+// dial connects a watcher to b.
+func dial(t *testing.T, b *Broadcaster) *Watcher {
+	t.Helper()
+	w, err := Dial(b.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { w.Close() })
+	w.conn.SetReadDeadline(time.Now().Add(waitTimeout))
+	return w
+}
+
+// waitSpectators waits until b serves n spectators.
+func waitSpectators(t *testing.T, b *Broadcaster, n int) {
+	t.Helper()
+	deadline := time.Now().Add(waitTimeout)
+	for b.Spectators() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d spectators, want %d", b.Spectators(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// states returns n states of a game in play, each different from the one
+// before.
+func states(n int) []game.DisplayState {
+	e := game.NewEngine(game.MarathonOptions(1, 5, 9))
+	moves := []func() bool{e.MoveLeft, e.RotateCW, e.MoveRight, e.MoveRight, e.SoftDrop}
+	var out []game.DisplayState
+	last := []byte(nil)
+	for i := 0; len(out) < n; i++ {
+		if e.Over() {
+			e = game.NewEngine(game.MarathonOptions(1, 5, uint64(i)))
+		}
+		if i%6 == 5 {
+			e.HardDrop()
+		} else {
+			moves[i%len(moves)]()
+		}
+		e.Advance(50 * time.Millisecond)
+		s := e.Display()
+		if data := encode(s); !bytes.Equal(data, last) {
+			out = append(out, s)
+			last = data
+		}
+	}
+	return out
+}
+
+// encode returns s as JSON, for comparing states as spectators get them.
+func encode(s game.DisplayState) []byte {
+	data, _ := json.Marshal(s)
+	return data
+}
+
+// sameState fails the test if got isn't want.
+func sameState(t *testing.T, got, want game.DisplayState, what string) {
+	t.Helper()
+	if g, w := encode(got), encode(want); !bytes.Equal(g, w) {
+		t.Fatalf("%s differs:\n got %s\nwant %s", what, g, w)
+	}
+}
+
+func TestLateJoiner(t *testing.T) {
+	b := listen(t)
+	ss := states(40)
+	for _, s := range ss[:10] {
+		b.Publish(s)
+	}
+
+	// A spectator joining mid-game gets the game as it stands first.
+	c, err := net.Dial("tcp", b.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.SetReadDeadline(time.Now().Add(waitTimeout))
+	dec := json.NewDecoder(bufio.NewReader(c))
+	var f Frame
+	if err := dec.Decode(&f); err != nil {
+		t.Fatal(err)
+	}
+	if !f.Snapshot || f.Version != ProtocolVersion || f.State == nil {
+		t.Fatalf("first frame is %+v, want a snapshot", f)
+	}
+	sameState(t, *f.State, ss[9], "snapshot")
+
+	// Then updates that only carry the rows that changed, which rebuild
+	// every state exactly.
+	w := dial(t, b)
+	waitSpectators(t, b, 2)
+	got, err := w.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameState(t, got, ss[9], "watcher snapshot")
+	for i, s := range ss[10:] {
+		b.Publish(s)
+		got, err := w.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameState(t, got, s, "state")
+
+		var f Frame
+		if err := dec.Decode(&f); err != nil {
+			t.Fatal(err)
+		}
+		if f.Snapshot || len(f.Rows) == len(s.Board) {
+			t.Fatalf("update %d resends the whole game", i)
+		}
+	}
+}
+
+func TestMaxSpectators(t *testing.T) {
+	b := listen(t)
+	for range MaxSpectators {
+		dial(t, b)
+	}
+	waitSpectators(t, b, MaxSpectators)
+
+	w := dial(t, b)
+	_, err := w.Next()
+	if err == nil || !strings.Contains(err.Error(), "spectators") {
+		t.Fatalf("spectator %d got %v, want to be turned away", MaxSpectators+1, err)
+	}
+	if n := b.Spectators(); n != MaxSpectators {
+		t.Errorf("%d spectators after turning one away, want %d", n, MaxSpectators)
+	}
+
+	// The ones already watching still get the game.
+	b.Publish(states(1)[0])
+	if n := b.Spectators(); n != MaxSpectators {
+		t.Errorf("%d spectators after publishing, want %d", n, MaxSpectators)
+	}
+}
+
+func TestStaleSpectatorCatchesUp(t *testing.T) {
+	b := listen(t)
+	ss := states(4 * queueSize)
+	b.Publish(ss[0])
+
+	// A pipe holds nothing, so a spectator that isn't reading backs up
+	// at once and the frames that don't fit in its queue are dropped.
+	server, client := net.Pipe()
+	defer client.Close()
+	b.add(server)
+	for _, s := range ss[1:] {
+		b.Publish(s)
+	}
+
+	var raw bytes.Buffer
+	client.SetReadDeadline(time.Now().Add(waitTimeout))
+	w := &Watcher{conn: client, dec: json.NewDecoder(io.TeeReader(client, &raw))}
+	want := encode(ss[len(ss)-1])
+	for {
+		got, err := w.Next()
+		if err != nil {
+			t.Fatalf("never caught up with the game: %v", err)
+		}
+		if bytes.Equal(encode(got), want) {
+			break
+		}
+	}
+
+	// It caught up from a fresh snapshot, not from the dropped updates.
+	snapshots := 0
+	dec := json.NewDecoder(&raw)
+	for {
+		var f Frame
+		if err := dec.Decode(&f); err != nil {
+			break
+		}
+		if f.Snapshot {
+			snapshots++
+		}
+	}
+	if snapshots < 2 {
+		t.Errorf("%d snapshots, want one to start and one to catch up", snapshots)
+	}
+}
@@ -0,0 +1,83 @@
+// Package suspend keeps the game a player left unfinished, so that it can
+// be continued later exactly where it was left. There is one such game
+// per data directory, and it can be taken back only once.
+package suspend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/meszmate/briks/internal/game"
+)
+
+// Version is the format version of the saved game.
+const Version = 1
+
+const file = "suspended.json"
+
+// Game is an unfinished game.
+type Game struct {
+	Version int           `json:"version"`
+	Date    time.Time     `json:"date"`
+	Engine  game.Snapshot `json:"engine"`
+
+	// Hinted and Practice carry over how the game was played, so that it
+	// is ranked the same once it ends.
+	Hinted   bool `json:"hinted,omitempty"`
+	Practice bool `json:"practice,omitempty"`
+
+	Finesse game.FinesseStats `json:"finesse"`
+	// Hits and Misses are the finesse trainer's score.
+	Hits   int `json:"hits,omitempty"`
+	Misses int `json:"misses,omitempty"`
+
+	// Replay is the recording so far, as written by replay.Encode; empty
+	// when the game isn't recorded.
+	Replay []byte `json:"replay,omitempty"`
+}
+
+// Save writes g to dir, replacing any game kept there.
+func Save(dir string, g *Game) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(g)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, file), data, 0644)
+}
+
+// Exists reports whether a game is kept in dir.
+func Exists(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, file))
+	return err == nil
+}
+
+// Take reads the game kept in dir and removes it, so the same game can't
+// be continued twice. A game that can't be read is removed all the same.
+func Take(dir string) (*Game, error) {
+	path := filepath.Join(dir, file)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil {
+		return nil, err
+	}
+	g := &Game{}
+	if err := json.Unmarshal(data, g); err != nil {
+		return nil, err
+	}
+	if g.Version != Version {
+		return nil, fmt.Errorf("saved game format %d, this build reads %d", g.Version, Version)
+	}
+	if g.Engine.State != game.StatePlaying {
+		return nil, errors.New("saved game has already ended")
+	}
+	return g, nil
+}
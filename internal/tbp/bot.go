@@ -0,0 +1,146 @@
+package tbp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/meszmate/briks/internal/ai"
+	"github.com/meszmate/briks/internal/game"
+)
+
+// BotName is what the built-in bot calls itself.
+const BotName = "briks"
+
+// Serve runs bot as a TBP bot reading messages from r and writing to w,
+// until it is told to quit or r ends.
+func Serve(r io.Reader, w io.Writer, bot *ai.Bot) error {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(infoMessage{
+		Type:     MsgInfo,
+		Name:     BotName,
+		Version:  "1",
+		Author:   "briks",
+		Features: []string{},
+	}); err != nil {
+		return err
+	}
+
+	// pos is the game being played, nil when there is none.
+	var pos *position
+	for {
+		var m Message
+		if err := dec.Decode(&m); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		var reply any
+		switch m.Type {
+		case MsgRules:
+			reply = Message{Type: MsgReady}
+		case MsgStart:
+			var err error
+			if pos, err = startPosition(m); err != nil {
+				return err
+			}
+		case MsgStop:
+			pos = nil
+		case MsgSuggest:
+			moves := []Move{}
+			if p, ok := suggest(bot, pos); ok {
+				moves = append(moves, MoveOf(p.Piece, p.TSpin))
+			}
+			reply = suggestionMessage{Type: MsgSuggestion, Moves: moves}
+		case MsgPlay:
+			if pos != nil && m.Move != nil {
+				if err := pos.play(*m.Move); err != nil {
+					return err
+				}
+			}
+		case MsgNewPiece:
+			if pos != nil {
+				pt, err := parsePiece(m.Piece)
+				if err != nil {
+					return err
+				}
+				pos.queue = append(pos.queue, pt)
+			}
+		case MsgQuit:
+			return nil
+		}
+
+		if reply != nil {
+			if err := enc.Encode(reply); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// startPosition reads the position a start message sets up.
+func startPosition(m Message) (*position, error) {
+	board, err := decodeBoard(m.Board)
+	if err != nil {
+		return nil, err
+	}
+	pos := &position{board: board}
+	if m.Hold != nil {
+		pt, err := parsePiece(*m.Hold)
+		if err != nil {
+			return nil, err
+		}
+		pos.hold = &pt
+	}
+	for _, name := range m.Queue {
+		pt, err := parsePiece(name)
+		if err != nil {
+			return nil, err
+		}
+		pos.queue = append(pos.queue, pt)
+	}
+	return pos, nil
+}
+
+// suggest picks the bot's move for the first piece in the queue.
+func suggest(bot *ai.Bot, pos *position) (ai.Placement, bool) {
+	if pos == nil || len(pos.queue) == 0 {
+		return ai.Placement{}, false
+	}
+	current := game.Piece{Type: pos.queue[0], Rotation: game.Rot0, Pos: pos.board.SpawnPosition(pos.queue[0])}
+	return bot.Best(ai.State{
+		Board:   pos.board,
+		Current: &current,
+		Hold:    pos.hold,
+		Next:    pos.queue[1:],
+	})
+}
+
+// play updates the position with a move the frontend played.
+func (pos *position) play(m Move) error {
+	piece, err := m.Piece()
+	if err != nil {
+		return err
+	}
+	if len(pos.queue) == 0 {
+		return errors.New("play with an empty queue")
+	}
+	hold := piece.Type != pos.queue[0]
+	if hold {
+		held := pos.hold
+		if held == nil && len(pos.queue) > 1 {
+			held = &pos.queue[1]
+		}
+		if held == nil || *held != piece.Type {
+			return fmt.Errorf("play of %s, which is neither the current nor the held piece", m.Location.Type)
+		}
+	}
+	next := pos.after(ai.Placement{Piece: piece, Hold: hold})
+	*pos = *next
+	return nil
+}
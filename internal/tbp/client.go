@@ -0,0 +1,207 @@
+package tbp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/meszmate/briks/internal/ai"
+)
+
+// handshakeTimeout bounds how long a launched bot may take to get ready.
+const handshakeTimeout = 10 * time.Second
+
+// quitTimeout is how long a launched bot gets to exit after quit before
+// it is killed.
+const quitTimeout = 2 * time.Second
+
+// Client is the frontend's connection to a bot. Requests may come from
+// any goroutine; they are answered one at a time.
+type Client struct {
+	// Name, Version and Author are what the bot says about itself.
+	Name    string
+	Version string
+	Author  string
+
+	cmd *exec.Cmd
+	in  io.WriteCloser
+	// out, if set, is closed first on Close, so that a bot stuck
+	// writing a reply nobody reads gets to quit.
+	out io.Closer
+	dec *json.Decoder
+	enc *json.Encoder
+
+	// mu serializes requests and guards running; wmu guards writes, so
+	// that Close can make the bot quit in the middle of a request.
+	mu  sync.Mutex
+	wmu sync.Mutex
+	// running is set while the bot has a game started.
+	running bool
+}
+
+// Launch starts a bot with the given command line and waits until it is
+// ready to play.
+func Launch(command string) (*Client, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, errors.New("no bot command")
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	// A bot that never gets ready is killed, which ends the handshake.
+	timer := time.AfterFunc(handshakeTimeout, func() { _ = cmd.Process.Kill() })
+	c, err := Connect(out, in)
+	if !timer.Stop() && err != nil {
+		err = fmt.Errorf("bot not ready after %v", handshakeTimeout)
+	}
+	if err != nil {
+		_ = in.Close()
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return nil, err
+	}
+	c.cmd = cmd
+	return c, nil
+}
+
+// Builtin runs the built-in AI as a bot on its own goroutine and
+// connects to it.
+func Builtin(bot *ai.Bot) (*Client, error) {
+	botIn, frontendOut := io.Pipe()
+	frontendIn, botOut := io.Pipe()
+	go func() {
+		err := Serve(botIn, botOut, bot)
+		_ = botOut.CloseWithError(err)
+		_ = botIn.Close()
+	}()
+	c, err := Connect(frontendIn, frontendOut)
+	if err != nil {
+		return nil, err
+	}
+	c.out = frontendIn
+	return c, nil
+}
+
+// Connect talks to a bot over r and w and waits until it is ready.
+func Connect(r io.Reader, w io.WriteCloser) (*Client, error) {
+	c := &Client{
+		in:  w,
+		dec: json.NewDecoder(bufio.NewReader(r)),
+		enc: json.NewEncoder(w),
+	}
+	info, err := c.receive()
+	if err != nil {
+		return nil, err
+	}
+	if info.Type != MsgInfo {
+		return nil, fmt.Errorf("expected info from the bot, got %q", info.Type)
+	}
+	c.Name, c.Version, c.Author = info.Name, info.Version, info.Author
+
+	if err := c.send(Message{Type: MsgRules}); err != nil {
+		return nil, err
+	}
+	m, err := c.receive()
+	switch {
+	case err != nil:
+		return nil, err
+	case m.Type == MsgError:
+		return nil, fmt.Errorf("bot refused the rules: %s", m.Reason)
+	case m.Type != MsgReady:
+		return nil, fmt.Errorf("expected ready from the bot, got %q", m.Type)
+	}
+	return c, nil
+}
+
+func (c *Client) send(m any) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	return c.enc.Encode(m)
+}
+
+func (c *Client) receive() (Message, error) {
+	var m Message
+	err := c.dec.Decode(&m)
+	if errors.Is(err, io.EOF) {
+		err = errors.New("the bot exited")
+	}
+	return m, err
+}
+
+// suggest sends msgs, after restarting the game with start if it is set,
+// and asks for moves.
+func (c *Client) suggest(start *startMessage, msgs []Message) ([]Move, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if start != nil {
+		if c.running {
+			if err := c.send(Message{Type: MsgStop}); err != nil {
+				return nil, err
+			}
+		}
+		if err := c.send(start); err != nil {
+			return nil, err
+		}
+		c.running = true
+	}
+	for _, m := range msgs {
+		if err := c.send(m); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.send(Message{Type: MsgSuggest}); err != nil {
+		return nil, err
+	}
+	for {
+		m, err := c.receive()
+		if err != nil {
+			return nil, err
+		}
+		// Anything else the bot says, such as info, is ignored.
+		if m.Type == MsgSuggestion {
+			return m.Moves, nil
+		}
+	}
+}
+
+// Close makes the bot quit. A launched bot that doesn't exit in time is
+// killed.
+func (c *Client) Close() error {
+	if c.out != nil {
+		_ = c.out.Close()
+	}
+	_ = c.send(Message{Type: MsgQuit})
+	c.wmu.Lock()
+	err := c.in.Close()
+	c.wmu.Unlock()
+	if c.cmd == nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() { done <- c.cmd.Wait() }()
+	select {
+	case err = <-done:
+	case <-time.After(quitTimeout):
+		_ = c.cmd.Process.Kill()
+		err = <-done
+	}
+	return err
+}
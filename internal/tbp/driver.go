@@ -0,0 +1,183 @@
+package tbp
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/meszmate/briks/internal/ai"
+	"github.com/meszmate/briks/internal/config"
+	"github.com/meszmate/briks/internal/game"
+)
+
+// ErrIllegalMove is returned when none of the moves a bot suggests can be
+// reached in the game.
+var ErrIllegalMove = errors.New("the bot suggested no move that can be played")
+
+// Driver lets a bot play one game. It keeps track of what the bot knows
+// about the game, telling it about new pieces as they show up in the
+// preview, and restarting it from the current position when the game
+// changed in ways it couldn't have predicted, such as garbage coming in.
+type Driver struct {
+	client *Client
+	// known is the game as the bot sees it, nil until it is started.
+	known *position
+	// pending are messages to send with the next request.
+	pending []Message
+	// pieces is how many pieces had been placed when moves were last
+	// asked for.
+	pieces int
+}
+
+// position is what TBP tells a bot about a game.
+type position struct {
+	board *game.Board
+	hold  *game.PieceType
+	// queue starts with the current piece.
+	queue []game.PieceType
+}
+
+// NewDriver creates a driver for a new game played by the client's bot.
+func NewDriver(c *Client) *Driver {
+	return &Driver{client: c}
+}
+
+// Name returns the name of the bot.
+func (d *Driver) Name() string {
+	return d.client.Name
+}
+
+// positionOf returns the position of a game in play.
+func positionOf(e *game.Engine) *position {
+	p := &position{board: e.Board.Clone(), queue: []game.PieceType{e.Current.Type}}
+	if e.HoldPiece != nil {
+		hold := *e.HoldPiece
+		p.hold = &hold
+	}
+	p.queue = append(p.queue, e.NextPieces()...)
+	return p
+}
+
+// follows reports whether p is what the bot expects after known, with
+// only more pieces revealed.
+func (p *position) follows(known *position) bool {
+	return known != nil &&
+		p.board.Equal(known.board) &&
+		(p.hold == nil) == (known.hold == nil) &&
+		(p.hold == nil || *p.hold == *known.hold) &&
+		len(p.queue) >= len(known.queue) &&
+		slices.Equal(p.queue[:len(known.queue)], known.queue)
+}
+
+// Suggest reads the engine's current piece and position and returns the
+// request for the bot's moves. The request may be made on another
+// goroutine, but only one at a time, and before Play is called. TBP
+// bots only play the standard board with SRS.
+func (d *Driver) Suggest(e *game.Engine) func() ([]Move, error) {
+	if !e.Board.Standard() || e.Board.System != game.SRS {
+		return func() ([]Move, error) {
+			return nil, fmt.Errorf("TBP bots play only the %dx%d board with SRS", game.DefaultWidth, game.DefaultHeight)
+		}
+	}
+	pos := positionOf(e)
+	msgs := d.pending
+	d.pending = nil
+
+	var start *startMessage
+	if pos.follows(d.known) {
+		for _, pt := range pos.queue[len(d.known.queue):] {
+			msgs = append(msgs, Message{Type: MsgNewPiece, Piece: pieceName(pt)})
+		}
+	} else {
+		start = &startMessage{
+			Type:       MsgStart,
+			Queue:      encodePieces(pos.queue),
+			Combo:      e.Scorer.Combo,
+			BackToBack: e.Scorer.BackToBack,
+			Board:      encodeBoard(e.Board),
+		}
+		if pos.hold != nil {
+			name := pieceName(*pos.hold)
+			start.Hold = &name
+		}
+		msgs = nil
+	}
+	d.known = pos
+	d.pieces = e.PiecesPlaced
+
+	return func() ([]Move, error) {
+		return d.client.suggest(start, msgs)
+	}
+}
+
+// Play picks the first of the suggested moves that can be reached from
+// where the engine's current piece is, and returns the inputs that play
+// it. A bot that suggests nothing has given up, and its piece is dropped
+// where it is. If the piece locked while the bot was thinking, there is
+// nothing to play and the moves are dropped.
+func (d *Driver) Play(e *game.Engine, moves []Move) ([]config.Action, error) {
+	if e.PiecesPlaced != d.pieces || e.State != game.StatePlaying {
+		d.known = nil
+		return nil, nil
+	}
+	if len(moves) == 0 {
+		d.known = nil
+		return []config.Action{config.ActionHardDrop}, nil
+	}
+	for _, m := range moves {
+		p, ok := d.reach(e, m)
+		if !ok {
+			continue
+		}
+		d.pending = append(d.pending, Message{Type: MsgPlay, Move: &m})
+		d.known = d.known.after(p)
+		return p.Actions(), nil
+	}
+	return nil, ErrIllegalMove
+}
+
+// reach finds how to play m in the engine, preferably with the T-Spin the
+// bot claims, though bots may not judge spins by the same rules.
+func (d *Driver) reach(e *game.Engine, m Move) (ai.Placement, bool) {
+	target, err := m.Piece()
+	if err != nil {
+		return ai.Placement{}, false
+	}
+	var found ai.Placement
+	ok := false
+	for _, p := range ai.Candidates(ai.StateOf(e)) {
+		if p.Piece.Type != target.Type || !sameCells(e.Board, p.Piece, target) {
+			continue
+		}
+		if p.TSpin == m.TSpin() {
+			return p, true
+		}
+		if !ok {
+			found, ok = p, true
+		}
+	}
+	return found, ok
+}
+
+func sameCells(board *game.Board, a, b game.Piece) bool {
+	ac, bc := board.PieceCells(&a), board.PieceCells(&b)
+	sortCells(ac)
+	sortCells(bc)
+	return slices.Equal(ac, bc)
+}
+
+// after returns the position the bot expects once p is played, holding
+// first if p is not the current piece.
+func (pos *position) after(p ai.Placement) *position {
+	next := &position{board: ai.Simulate(pos.board, p).Board, hold: pos.hold}
+	queue := pos.queue
+	if p.Hold {
+		held := queue[0]
+		if pos.hold == nil {
+			queue = queue[1:]
+		}
+		next.hold = &held
+	}
+	next.queue = slices.Clone(queue[1:])
+	return next
+}
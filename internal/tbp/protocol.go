@@ -0,0 +1,303 @@
+// Package tbp speaks the Tetris Bot Protocol, the JSON message protocol
+// external Tetris bots use over stdin and stdout.
+//
+// The frontend side (Client and Driver) launches a bot as a subprocess
+// and lets it play a game.Engine. The bot side (Serve) runs the built-in
+// AI as a TBP bot, so the two can be tested against each other offline.
+package tbp
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/meszmate/briks/internal/game"
+)
+
+// Message types.
+const (
+	// MsgInfo is the bot's first message, naming it.
+	MsgInfo = "info"
+	// MsgRules tells the bot the rules of the game; it answers with
+	// MsgReady or MsgError.
+	MsgRules = "rules"
+	// MsgReady accepts the rules.
+	MsgReady = "ready"
+	// MsgError refuses the rules; Reason says why.
+	MsgError = "error"
+	// MsgStart starts a game from a given position.
+	MsgStart = "start"
+	// MsgStop ends the game the bot is playing.
+	MsgStop = "stop"
+	// MsgSuggest asks the bot for moves; it answers with MsgSuggestion.
+	MsgSuggest = "suggest"
+	// MsgSuggestion lists moves, best first.
+	MsgSuggestion = "suggestion"
+	// MsgPlay tells the bot which move was played.
+	MsgPlay = "play"
+	// MsgNewPiece adds a piece to the end of the queue.
+	MsgNewPiece = "new_piece"
+	// MsgQuit makes the bot exit.
+	MsgQuit = "quit"
+)
+
+// Message is any TBP message. Only the fields of its type are set.
+// Messages whose fields must be sent even when empty have their own
+// types: infoMessage, startMessage and suggestionMessage.
+type Message struct {
+	Type string `json:"type"`
+
+	Name     string   `json:"name,omitempty"`
+	Version  string   `json:"version,omitempty"`
+	Author   string   `json:"author,omitempty"`
+	Features []string `json:"features,omitempty"`
+	Reason   string   `json:"reason,omitempty"`
+
+	Hold       *string     `json:"hold,omitempty"`
+	Queue      []string    `json:"queue,omitempty"`
+	Combo      int         `json:"combo,omitempty"`
+	BackToBack bool        `json:"back_to_back,omitempty"`
+	Board      [][]*string `json:"board,omitempty"`
+
+	Moves []Move `json:"moves,omitempty"`
+	Move  *Move  `json:"move,omitempty"`
+	Piece string `json:"piece,omitempty"`
+}
+
+type infoMessage struct {
+	Type     string   `json:"type"`
+	Name     string   `json:"name"`
+	Version  string   `json:"version"`
+	Author   string   `json:"author"`
+	Features []string `json:"features"`
+}
+
+type startMessage struct {
+	Type       string      `json:"type"`
+	Hold       *string     `json:"hold"`
+	Queue      []string    `json:"queue"`
+	Combo      int         `json:"combo"`
+	BackToBack bool        `json:"back_to_back"`
+	Board      [][]*string `json:"board"`
+}
+
+type suggestionMessage struct {
+	Type  string `json:"type"`
+	Moves []Move `json:"moves"`
+}
+
+// Move is a placement: where the piece locks and how it got there.
+type Move struct {
+	Location Location `json:"location"`
+	// Spin is "none", "mini" or "full".
+	Spin string `json:"spin"`
+}
+
+// Location places a piece by the cell it rotates around, counting x from
+// the left wall and y from the floor.
+type Location struct {
+	Type        string `json:"type"`
+	Orientation string `json:"orientation"`
+	X           int    `json:"x"`
+	Y           int    `json:"y"`
+}
+
+// BoardRows is how many rows a TBP board has. Rows above the engine's
+// board are always empty.
+const BoardRows = 40
+
+// engineRows is how many rows the engine's board has. TBP only knows the
+// standard 10 wide board.
+const engineRows = game.DefaultHeight + game.BufferRows
+
+var pieceNames = [...]string{
+	game.PieceI: "I",
+	game.PieceO: "O",
+	game.PieceT: "T",
+	game.PieceS: "S",
+	game.PieceZ: "Z",
+	game.PieceJ: "J",
+	game.PieceL: "L",
+}
+
+var orientations = [...]string{
+	game.Rot0: "north",
+	game.Rot1: "east",
+	game.Rot2: "south",
+	game.Rot3: "west",
+}
+
+var spins = [...]string{
+	game.TSpinNone: "none",
+	game.TSpinMini: "mini",
+	game.TSpinFull: "full",
+}
+
+// pieceName returns the TBP name of a piece type.
+func pieceName(pt game.PieceType) string {
+	return pieceNames[pt]
+}
+
+// parsePiece returns the piece type with the given TBP name.
+func parsePiece(name string) (game.PieceType, error) {
+	i := slices.Index(pieceNames[:], name)
+	if i < 0 {
+		return 0, fmt.Errorf("unknown piece %q", name)
+	}
+	return game.PieceType(i), nil
+}
+
+// centers holds where the cell a piece rotates around in TBP sits in the
+// engine's 4x4 piece box under SRS, per type and rotation.
+var centers = func() (c [len(pieceNames)][4]game.Position) {
+	// The cells of each piece pointing north, relative to its center,
+	// with y pointing up.
+	north := [...][4][2]int{
+		game.PieceI: {{-1, 0}, {0, 0}, {1, 0}, {2, 0}},
+		game.PieceO: {{0, 0}, {1, 0}, {0, 1}, {1, 1}},
+		game.PieceT: {{-1, 0}, {0, 0}, {1, 0}, {0, 1}},
+		game.PieceS: {{-1, 0}, {0, 0}, {0, 1}, {1, 1}},
+		game.PieceZ: {{-1, 1}, {0, 1}, {0, 0}, {1, 0}},
+		game.PieceJ: {{-1, 0}, {0, 0}, {1, 0}, {-1, 1}},
+		game.PieceL: {{-1, 0}, {0, 0}, {1, 0}, {1, 1}},
+	}
+	for pt, cells := range north {
+		for rot := range 4 {
+			tbp := make([]game.Position, 4)
+			for i, xy := range cells {
+				tbp[i] = game.Position{Row: -xy[1], Col: xy[0]}
+			}
+			engine := slices.Clone(game.SRS.Shape(game.PieceType(pt), game.Rotation(rot)))
+			sortCells(tbp)
+			sortCells(engine)
+			// Both list the same shape, so they differ by where the
+			// center is in the box.
+			c[pt][rot] = game.Position{Row: engine[0].Row - tbp[0].Row, Col: engine[0].Col - tbp[0].Col}
+			for i := range engine {
+				if engine[i].Row-tbp[i].Row != c[pt][rot].Row || engine[i].Col-tbp[i].Col != c[pt][rot].Col {
+					panic(fmt.Sprintf("tbp: %s %s doesn't match the engine's rotation", pieceNames[pt], orientations[rot]))
+				}
+			}
+			// Rotate clockwise for the next orientation.
+			for i, xy := range cells {
+				cells[i] = [2]int{xy[1], -xy[0]}
+			}
+		}
+	}
+	return c
+}()
+
+func sortCells(cells []game.Position) {
+	slices.SortFunc(cells, func(a, b game.Position) int {
+		if a.Row != b.Row {
+			return a.Row - b.Row
+		}
+		return a.Col - b.Col
+	})
+}
+
+// MoveOf returns the move that locks p with the given T-Spin.
+func MoveOf(p game.Piece, spin game.TSpinKind) Move {
+	c := centers[p.Type][p.Rotation]
+	return Move{
+		Location: Location{
+			Type:        pieceName(p.Type),
+			Orientation: orientations[p.Rotation],
+			X:           p.Pos.Col + c.Col,
+			Y:           engineRows - 1 - (p.Pos.Row + c.Row),
+		},
+		Spin: spins[spin],
+	}
+}
+
+// Piece returns the piece where the move locks it.
+func (m Move) Piece() (game.Piece, error) {
+	pt, err := parsePiece(m.Location.Type)
+	if err != nil {
+		return game.Piece{}, err
+	}
+	rot := slices.Index(orientations[:], m.Location.Orientation)
+	if rot < 0 {
+		return game.Piece{}, fmt.Errorf("unknown orientation %q", m.Location.Orientation)
+	}
+	c := centers[pt][rot]
+	return game.Piece{
+		Type:     pt,
+		Rotation: game.Rotation(rot),
+		Pos: game.Position{
+			Row: engineRows - 1 - m.Location.Y - c.Row,
+			Col: m.Location.X - c.Col,
+		},
+	}, nil
+}
+
+// TSpin returns the T-Spin the move claims.
+func (m Move) TSpin() game.TSpinKind {
+	if i := slices.Index(spins[:], m.Spin); i >= 0 {
+		return game.TSpinKind(i)
+	}
+	return game.TSpinNone
+}
+
+// encodeBoard returns a board as TBP rows, bottom row first.
+func encodeBoard(b *game.Board) [][]*string {
+	garbage := "G"
+	rows := make([][]*string, BoardRows)
+	for y := range rows {
+		rows[y] = make([]*string, b.Width)
+		r := engineRows - 1 - y
+		if r < 0 {
+			continue
+		}
+		for col, cell := range b.Cells[r] {
+			switch {
+			case cell == game.Empty:
+			case cell == game.ColorGarbage:
+				rows[y][col] = &garbage
+			default:
+				name := pieceName(cellPiece(cell))
+				rows[y][col] = &name
+			}
+		}
+	}
+	return rows
+}
+
+// decodeBoard reads a board sent as TBP rows.
+func decodeBoard(rows [][]*string) (*game.Board, error) {
+	b := game.NewBoard(game.DefaultWidth, game.DefaultHeight)
+	for y, row := range rows {
+		r := engineRows - 1 - y
+		for col, cell := range row {
+			if cell == nil {
+				continue
+			}
+			if r < 0 || col >= b.Width {
+				return b, fmt.Errorf("cell %d,%d is outside the %dx%d board", col, y, b.Width, engineRows)
+			}
+			b.Cells[r][col] = game.ColorGarbage
+			if pt, err := parsePiece(*cell); err == nil {
+				b.Cells[r][col] = game.PieceColor(pt)
+			}
+		}
+	}
+	return b, nil
+}
+
+// cellPiece returns the piece type that leaves cells of the given color.
+func cellPiece(c game.CellColor) game.PieceType {
+	for _, pt := range game.AllPieceTypes {
+		if game.PieceColor(pt) == c {
+			return pt
+		}
+	}
+	return game.PieceI
+}
+
+// encodePieces returns piece types by their TBP names.
+func encodePieces(pieces []game.PieceType) []string {
+	names := make([]string, len(pieces))
+	for i, pt := range pieces {
+		names[i] = pieceName(pt)
+	}
+	return names
+}
@@ -0,0 +1,169 @@
+package tbp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/meszmate/briks/internal/ai"
+	"github.com/meszmate/briks/internal/game"
+	"github.com/meszmate/briks/internal/replay"
+)
+
+// recorder keeps a copy of the messages written through it.
+type recorder struct {
+	io.WriteCloser
+	buf bytes.Buffer
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.buf.Write(p)
+	return r.WriteCloser.Write(p)
+}
+
+// types returns the types of newline-delimited JSON messages.
+func types(t *testing.T, data []byte) []string {
+	t.Helper()
+	var out []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		var m Message
+		if err := json.Unmarshal(sc.Bytes(), &m); err != nil {
+			t.Fatalf("bad message %q: %v", sc.Text(), err)
+		}
+		out = append(out, m.Type)
+	}
+	return out
+}
+
+// connectBuiltin connects to the built-in bot like Builtin, recording
+// what each side sends.
+func connectBuiltin(t *testing.T) (c *Client, sent, received *bytes.Buffer) {
+	t.Helper()
+	botIn, frontendOut := io.Pipe()
+	frontendIn, botOut := io.Pipe()
+	served := make(chan error, 1)
+	go func() {
+		err := Serve(botIn, botOut, ai.New(nil))
+		_ = botOut.CloseWithError(err)
+		served <- err
+	}()
+
+	out := &recorder{WriteCloser: frontendOut}
+	received = &bytes.Buffer{}
+	c, err := Connect(io.TeeReader(frontendIn, received), out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.out = frontendIn
+	t.Cleanup(func() {
+		c.Close()
+		if err := <-served; err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	})
+	return c, &out.buf, received
+}
+
+func TestDriverPlaysBuiltin(t *testing.T) {
+	c, sent, received := connectBuiltin(t)
+	if c.Name != BotName {
+		t.Errorf("Name = %q, want %q", c.Name, BotName)
+	}
+
+	const pieces = 60
+	e := game.NewEngine(game.MarathonOptions(1, 5, 7))
+	d := NewDriver(c)
+	requests := 0
+	for e.PiecesPlaced < pieces && e.State == game.StatePlaying {
+		// Garbage the bot can't predict makes the driver restart it.
+		if requests == 20 {
+			e.ReceiveGarbage(3)
+		}
+		moves, err := d.Suggest(e)()
+		if err != nil {
+			t.Fatal(err)
+		}
+		requests++
+		if len(moves) == 0 {
+			t.Fatalf("the bot gave up after %d pieces", e.PiecesPlaced)
+		}
+		before := e.PiecesPlaced
+		actions, err := d.Play(e, moves)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, a := range actions {
+			replay.Apply(e, a)
+		}
+		if e.PiecesPlaced != before+1 {
+			t.Fatalf("request %d placed %d pieces, want 1", requests, e.PiecesPlaced-before)
+		}
+	}
+	if e.PiecesPlaced < pieces {
+		t.Fatalf("game ended after %d pieces", e.PiecesPlaced)
+	}
+	c.Close()
+
+	// The frontend sets the rules, then starts the game and asks for
+	// moves; after that each request reports the move played and the new
+	// pieces, or restarts the game.
+	got := types(t, sent.Bytes())
+	if len(got) < 3 || got[0] != MsgRules || got[1] != MsgStart || got[2] != MsgSuggest {
+		t.Fatalf("frontend opened with %v, want rules, start, suggest", got[:min(len(got), 3)])
+	}
+	if got[len(got)-1] != MsgQuit {
+		t.Errorf("frontend ended with %q, want quit", got[len(got)-1])
+	}
+	var request []string
+	suggests, restarts := 1, 0
+	for _, typ := range got[3 : len(got)-1] {
+		request = append(request, typ)
+		if typ != MsgSuggest {
+			continue
+		}
+		suggests++
+		switch {
+		case len(request) == 3 && request[0] == MsgStop && request[1] == MsgStart:
+			restarts++
+		case len(request) >= 3 && request[0] == MsgPlay && allOf(request[1:len(request)-1], MsgNewPiece):
+		default:
+			t.Errorf("request %d is %v", suggests, request)
+		}
+		request = nil
+	}
+	if suggests != requests {
+		t.Errorf("%d suggests sent, want %d", suggests, requests)
+	}
+	if restarts == 0 {
+		t.Error("the game was never restarted after garbage")
+	}
+
+	// The bot introduces itself, accepts the rules and answers each
+	// request.
+	replies := types(t, received.Bytes())
+	if len(replies) != 2+requests || replies[0] != MsgInfo || replies[1] != MsgReady || !allOf(replies[2:], MsgSuggestion) {
+		t.Errorf("bot replied %v", replies)
+	}
+}
+
+func TestSuggestNonStandard(t *testing.T) {
+	c, _, _ := connectBuiltin(t)
+	opts := game.MarathonOptions(1, 5, 7)
+	opts.Width = 12
+	if _, err := NewDriver(c).Suggest(game.NewEngine(opts))(); err == nil {
+		t.Error("Suggest on a 12-wide board succeeded")
+	}
+}
+
+func allOf(s []string, v string) bool {
+	for _, x := range s {
+		if x != v {
+			return false
+		}
+	}
+	return true
+}
@@ -1,9 +1,21 @@
 package tui
 
 import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/meszmate/briks/internal/config"
+	"github.com/meszmate/briks/internal/game"
+	"github.com/meszmate/briks/internal/netplay"
+	"github.com/meszmate/briks/internal/replay"
+	"github.com/meszmate/briks/internal/spectate"
+	"github.com/meszmate/briks/internal/suspend"
+	"github.com/meszmate/briks/internal/tbp"
 	"github.com/meszmate/briks/internal/theme"
 )
 
@@ -18,8 +30,16 @@ const (
 	ScreenSettings
 	ScreenHighScores
 	ScreenKeyBinds
+	ScreenReplays
+	ScreenReplay
+	ScreenModes
+	ScreenVersusResult
+	ScreenNetWait
+	ScreenWatch
 )
 
+// MinWidth and MinHeight are the terminal size needed for the menus and
+// a standard board; bigger boards need more.
 const (
 	MinWidth  = 60
 	MinHeight = 28
@@ -34,8 +54,17 @@ type App struct {
 	cfg        *config.Config
 	keys       *config.KeyBindings
 	highScores *config.HighScores
+	bestTimes  *config.BestTimes
 	styles     Styles
 	rainbow    *theme.RainbowState
+	out        io.Writer
+
+	// seed, when set, is used for every game instead of a random one.
+	seed *uint64
+	// mode is the mode of the current or last game.
+	mode game.Mode
+	// player names the scores of this app on a shared server.
+	player string
 
 	menu     MenuModel
 	game     GameModel
@@ -44,10 +73,35 @@ type App struct {
 	settings SettingsModel
 	scores   HighScoresModel
 	keyBinds KeyBindsModel
+	replays  ReplaysModel
+	playback ReplayModel
+	modes    ModeSelectModel
+	result   VersusResultModel
+	watch    WatchModel
+	demo     DemoModel
+
+	// attract is set while the demo plays on the main menu, which it
+	// starts by itself once no key has been pressed for demoIdleDelay.
+	attract bool
+	// lastKey is when a key was last pressed.
+	lastKey time.Time
+
+	// match is the versus match in progress.
+	match VersusMatch
+	// session is the connection to an online opponent, if any.
+	session *netplay.Session
+	// broadcast, if set, shows the games played to spectators.
+	broadcast *spectate.Broadcaster
+	// bot, if set, plays the demo and versus instead of the built-in AI
+	// and the second player.
+	bot *tbp.Client
+
+	// quitAfterReplay is set when the app was started just to play a replay.
+	quitAfterReplay bool
 }
 
 // NewApp creates the root application model.
-func NewApp(cfg *config.Config, keys *config.KeyBindings, hs *config.HighScores) App {
+func NewApp(cfg *config.Config, keys *config.KeyBindings, hs *config.HighScores, bt *config.BestTimes) App {
 	t := theme.GetTheme(cfg.Theme)
 	s := NewStyles(t)
 	rb := theme.NewRainbowState()
@@ -57,23 +111,109 @@ func NewApp(cfg *config.Config, keys *config.KeyBindings, hs *config.HighScores)
 		cfg:        cfg,
 		keys:       keys,
 		highScores: hs,
+		bestTimes:  bt,
 		styles:     s,
 		rainbow:    rb,
+		out:        os.Stdout,
+		lastKey:    time.Now(),
 	}
 
-	app.menu = NewMenuModel(s)
+	app.menu = app.newMenu()
 	app.settings = NewSettingsModel(cfg, s)
-	app.scores = NewHighScoresModel(hs, s)
+	app.scores = NewHighScoresModel(hs, bt, cfg, s)
 	app.keyBinds = NewKeyBindsModel(keys, s)
 
 	return app
 }
 
+// FixSeed makes every game use the given seed, so the piece sequence
+// repeats from game to game.
+func (a *App) FixSeed(seed uint64) {
+	a.seed = &seed
+}
+
+// newGame starts a game of the current mode with the fixed seed or a
+// random one. Versus games start a round between the two key profiles,
+// or against the bot if there is one.
+func (a App) newGame() GameModel {
+	seed := game.RandomSeed()
+	if a.seed != nil {
+		seed = *a.seed
+	}
+	opts := gameOptions(a.mode, a.cfg, seed)
+	if a.mode == game.ModeVersus && a.bot != nil {
+		// TBP bots only play the standard board with SRS.
+		opts.Width, opts.Height, opts.Rotation = 0, 0, nil
+		return NewBotModel(a.cfg, a.keys, a.rainbow, opts, a.bot)
+	}
+	if a.mode == game.ModeVersus {
+		keys := [2]*config.KeyBindings{
+			config.ProfileKeyBindings(a.cfg.P1Keys, a.keys),
+			config.ProfileKeyBindings(a.cfg.P2Keys, a.keys),
+		}
+		return NewVersusModel(a.cfg, keys, a.rainbow, opts)
+	}
+	return NewGameModel(a.cfg, a.keys, a.rainbow, opts)
+}
+
+// SetBot makes a TBP bot play the demo and be the opponent in versus.
+func (a *App) SetBot(c *tbp.Client) {
+	a.bot = c
+}
+
+// SetPlayer names the player whose scores this app records, for
+// leaderboards shared by several players. Characters that aren't
+// printable are dropped.
+func (a *App) SetPlayer(name string) {
+	a.player = cleanName(name)
+}
+
+// SetOutput sets the terminal the app is drawn on, when it isn't stdout.
+func (a *App) SetOutput(w io.Writer) {
+	a.out = w
+}
+
+// PlayReplay makes the app open straight into playback of r and quit
+// when playback is closed.
+func (a *App) PlayReplay(r *replay.Replay) {
+	a.playback = NewReplayModel(r)
+	a.screen = ScreenReplay
+	a.quitAfterReplay = true
+}
+
+// Broadcast shows every game played to the spectators of b.
+func (a *App) Broadcast(b *spectate.Broadcaster) {
+	a.broadcast = b
+}
+
+// Watch makes the app open straight into following the game broadcast
+// at addr, and quit when it is closed.
+func (a *App) Watch(w *spectate.Watcher, addr string) {
+	a.watch = NewWatchModel(w, addr)
+	a.screen = ScreenWatch
+}
+
 func (a App) Init() tea.Cmd {
-	return nil
+	cmds := []tea.Cmd{enableKeyboardEnhancements(a.out), idleTick()}
+	switch a.screen {
+	case ScreenReplay:
+		cmds = append(cmds, a.playback.Init())
+	case ScreenGame:
+		cmds = append(cmds, a.game.Init())
+	case ScreenWatch:
+		cmds = append(cmds, a.watch.Init())
+	}
+	if a.session != nil {
+		cmds = append(cmds, waitNet(a.session))
+	}
+	return tea.Batch(cmds...)
 }
 
 func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if translated, ok := translateKeyboardMsg(msg); ok {
+		msg = translated
+	}
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		a.width = msg.Width
@@ -81,9 +221,27 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a, nil
 
 	case tea.KeyMsg:
+		a.lastKey = time.Now()
 		if msg.String() == "ctrl+c" {
+			if a.screen == ScreenGame || a.screen == ScreenPause {
+				a.suspendGame()
+			}
+			a.leaveNetplay()
 			return a, tea.Quit
 		}
+
+	case NetMsg:
+		return a.updateNet(msg.Message)
+
+	case BotMovesMsg:
+		return a.updateBot(msg)
+
+	case IdleTickMsg:
+		if a.screen == ScreenMenu && !a.attract && msg.Time.Sub(a.lastKey) >= demoIdleDelay {
+			m, cmd := a.startDemo()
+			return m, tea.Batch(cmd, idleTick())
+		}
+		return a, idleTick()
 	}
 
 	switch a.screen {
@@ -101,27 +259,55 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a.updateHighScores(msg)
 	case ScreenKeyBinds:
 		return a.updateKeyBinds(msg)
+	case ScreenReplays:
+		return a.updateReplays(msg)
+	case ScreenReplay:
+		return a.updateReplay(msg)
+	case ScreenModes:
+		return a.updateModes(msg)
+	case ScreenVersusResult:
+		return a.updateVersusResult(msg)
+	case ScreenNetWait:
+		return a.updateNetWait(msg)
+	case ScreenWatch:
+		return a.updateWatch(msg)
 	}
 
 	return a, nil
 }
 
 func (a App) View() string {
-	if a.width < MinWidth || a.height < MinHeight {
+	minWidth, minHeight := a.minSize()
+	if a.width < minWidth || a.height < minHeight {
 		msg := lipgloss.NewStyle().
 			Foreground(a.styles.Theme.Main).
 			Bold(true).
-			Render("Terminal too small\nMinimum: 60x28")
+			Render(fmt.Sprintf("Terminal too small\nMinimum: %dx%d", minWidth, minHeight))
 		return lipgloss.Place(a.width, a.height, lipgloss.Center, lipgloss.Center, msg)
 	}
+	if a.screen == ScreenGame && a.game.versus() {
+		if w := versusMinWidth(a.game.engine().Board); a.width < w {
+			msg := lipgloss.NewStyle().
+				Foreground(a.styles.Theme.Main).
+				Bold(true).
+				Render(fmt.Sprintf("Terminal too small for versus\nMinimum width: %d", w))
+			return lipgloss.Place(a.width, a.height, lipgloss.Center, lipgloss.Center, msg)
+		}
+	}
 
 	var content string
 
 	switch a.screen {
 	case ScreenMenu:
 		content = a.menu.View(a.styles)
+		if a.attract {
+			content = a.attractView(content)
+		}
 	case ScreenGame:
 		content = a.game.View(a.styles, a.cfg, a.rainbow)
+		if a.broadcast != nil {
+			content = lipgloss.JoinVertical(lipgloss.Center, content, a.broadcastStatus())
+		}
 	case ScreenPause:
 		content = a.pause.View(a.styles)
 	case ScreenGameOver:
@@ -132,11 +318,53 @@ func (a App) View() string {
 		content = a.scores.View(a.styles)
 	case ScreenKeyBinds:
 		content = a.keyBinds.View(a.styles)
+	case ScreenReplays:
+		content = a.replays.View(a.styles)
+	case ScreenReplay:
+		content = a.playback.View(a.styles, a.cfg, a.rainbow)
+	case ScreenModes:
+		content = a.modes.View(a.styles, a.cfg)
+	case ScreenVersusResult:
+		content = a.result.View(a.styles)
+	case ScreenNetWait:
+		content = netWaitView(a.styles)
+	case ScreenWatch:
+		content = a.watch.View(a.styles, a.cfg, a.rainbow)
 	}
 
 	return lipgloss.Place(a.width, a.height, lipgloss.Center, lipgloss.Center, content)
 }
 
+// shownBoard returns the board on screen, or nil if there is none.
+func (a App) shownBoard() *game.Board {
+	switch a.screen {
+	case ScreenMenu:
+		if a.attract {
+			return a.demo.engine.Board
+		}
+	case ScreenGame:
+		return a.game.engine().Board
+	case ScreenReplay:
+		return a.playback.player.Engine.Board
+	case ScreenWatch:
+		if a.watch.engine != nil {
+			return a.watch.engine.Board
+		}
+	}
+	return nil
+}
+
+// minSize returns the terminal size needed for the current screen: a
+// playfield is its board plus 40 columns of panels and 8 rows of borders
+// and footer.
+func (a App) minSize() (int, int) {
+	b := a.shownBoard()
+	if b == nil {
+		return MinWidth, MinHeight
+	}
+	return max(MinWidth, 2*b.Width+40), max(MinHeight, b.Height+8)
+}
+
 // refreshedStyles returns new styles from the current config theme.
 func refreshedStyles(cfg *config.Config) Styles {
 	t := theme.GetTheme(cfg.Theme)
@@ -146,6 +374,9 @@ func refreshedStyles(cfg *config.Config) Styles {
 // Screen transition helpers.
 
 func (a App) updateMenu(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if a.attract {
+		return a.updateDemo(msg)
+	}
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
@@ -157,20 +388,30 @@ func (a App) updateMenu(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.menu.Prev()
 		case "enter", "l":
 			switch a.menu.Selected() {
-			case 0: // Play
-				a.game = NewGameModel(a.cfg, a.keys, a.rainbow)
-				a.screen = ScreenGame
-				return a, a.game.Init()
-			case 1: // Settings
+			case menuContinue:
+				return a.continueGame()
+			case "Play":
+				a.modes = NewModeSelectModel(a.mode, a.bot)
+				a.screen = ScreenModes
+			case "Settings":
 				a.settings = NewSettingsModel(a.cfg, a.styles)
 				a.screen = ScreenSettings
-			case 2: // High Scores
-				a.scores = NewHighScoresModel(a.highScores, a.styles)
+			case "High Scores":
+				a.scores = NewHighScoresModel(a.highScores, a.bestTimes, a.cfg, a.styles)
 				a.screen = ScreenHighScores
-			case 3: // Key Bindings
+			case "Replays":
+				dir, err := a.cfg.DataDir()
+				if err != nil {
+					return a, nil
+				}
+				a.replays = NewReplaysModel(dir)
+				a.screen = ScreenReplays
+			case "Demo":
+				return a.startDemo()
+			case "Key Bindings":
 				a.keyBinds = NewKeyBindsModel(a.keys, a.styles)
 				a.screen = ScreenKeyBinds
-			case 4: // Quit
+			case "Quit":
 				return a, tea.Quit
 			}
 		}
@@ -179,17 +420,40 @@ func (a App) updateMenu(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (a App) updateGame(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if a.game.gameOver {
+		// An online guest waits here for the host to decide the round.
+		return a, nil
+	}
 	var cmd tea.Cmd
-	a.game, cmd = a.game.Update(msg, a.keys)
+	a.game, cmd = a.game.Update(msg)
+	if a.broadcast != nil {
+		a.broadcast.Publish(a.game.engine().Display())
+	}
 
 	if a.game.paused {
-		a.pause = NewPauseModel()
+		a.pause = NewPauseModel(a.game.suspended() != nil)
 		a.screen = ScreenPause
 		return a, nil
 	}
 
+	if a.game.botErr != nil {
+		return a.abandonBot()
+	}
+
+	if a.game.gameOver && a.game.versus() {
+		if a.game.session() != nil {
+			return a.endNetRound()
+		}
+		a.result = NewVersusResultModel(a.game, &a.match, a.game.winner())
+		a.screen = ScreenVersusResult
+		return a, nil
+	}
+
 	if a.game.gameOver {
-		a.gameOver = NewGameOverModel(a.game.engine, a.highScores)
+		if dir, err := a.cfg.DataDir(); err == nil {
+			a.game.saveReplay(dir)
+		}
+		a.gameOver = NewGameOverModel(a.game.engine(), a.highScores, a.bestTimes, a.player, a.game.hinted(), a.game.practiced(), a.game.finesse())
 		a.screen = ScreenGameOver
 		return a, nil
 	}
@@ -203,13 +467,14 @@ func (a App) updatePause(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 		case "p", "esc":
 			a.screen = ScreenGame
-			a.game.paused = false
+			a.game.resume()
 			return a, a.game.resumeTick()
 		case "q":
+			a.suspendGame()
 			a.screen = ScreenMenu
-			a.menu = NewMenuModel(a.styles)
+			a.menu = a.newMenu()
 		case "r":
-			a.game = NewGameModel(a.cfg, a.keys, a.rainbow)
+			a.game = a.newGame()
 			a.screen = ScreenGame
 			return a, a.game.Init()
 		}
@@ -217,17 +482,69 @@ func (a App) updatePause(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return a, nil
 }
 
+// newMenu creates the main menu, offering to continue the suspended game
+// if there is one.
+func (a App) newMenu() MenuModel {
+	dir, err := a.cfg.DataDir()
+	return NewMenuModel(a.styles, err == nil && suspend.Exists(dir))
+}
+
+// suspendGame keeps the game in play, if it can be continued later, for
+// the Continue item of the menu.
+func (a App) suspendGame() {
+	s := a.game.suspended()
+	if s == nil {
+		return
+	}
+	if dir, err := a.cfg.DataDir(); err == nil {
+		_ = suspend.Save(dir, s)
+	}
+}
+
+// continueGame resumes the suspended game, paused so the player can get
+// ready. The saved game is gone once taken, so a game that goes badly
+// can't be continued again from the same point. If it can't be resumed,
+// it is discarded and the menu is shown again without it, saying why.
+func (a App) continueGame() (tea.Model, tea.Cmd) {
+	dir, err := a.cfg.DataDir()
+	if err != nil {
+		return a, nil
+	}
+	s, err := suspend.Take(dir)
+	if err != nil {
+		return a.cantContinue(err)
+	}
+	g, err := ResumeGameModel(a.cfg, a.keys, a.rainbow, s)
+	if err != nil {
+		return a.cantContinue(err)
+	}
+	a.game = g
+	a.mode = s.Engine.Mode
+	a.game.paused = true
+	a.pause = NewPauseModel(true)
+	a.screen = ScreenPause
+	return a, nil
+}
+
+// cantContinue shows the menu without the suspended game, which is gone,
+// and why it couldn't be continued.
+func (a App) cantContinue(err error) (tea.Model, tea.Cmd) {
+	a.menu = a.newMenu()
+	a.menu.notice = "The saved game can't be continued: " + err.Error()
+	return a, nil
+}
+
 func (a App) updateGameOver(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "r":
-			a.game = NewGameModel(a.cfg, a.keys, a.rainbow)
+			a.game = a.newGame()
 			a.screen = ScreenGame
 			return a, a.game.Init()
 		case "q", "esc", "enter":
 			a.screen = ScreenMenu
-			a.menu = NewMenuModel(a.styles)
+			a.menu = a.newMenu()
 		}
 	}
 	return a, nil
@@ -241,7 +558,7 @@ func (a App) updateSettings(msg tea.Msg) (tea.Model, tea.Cmd) {
 			_ = a.cfg.Save()
 			a.styles = refreshedStyles(a.cfg)
 			a.screen = ScreenMenu
-			a.menu = NewMenuModel(a.styles)
+			a.menu = a.newMenu()
 			return a, nil
 		default:
 			a.settings = a.settings.Update(msg, a.cfg)
@@ -258,7 +575,9 @@ func (a App) updateHighScores(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 		case "esc", "q", "enter":
 			a.screen = ScreenMenu
-			a.menu = NewMenuModel(a.styles)
+			a.menu = a.newMenu()
+		default:
+			a.scores = a.scores.Update(msg)
 		}
 	}
 	return a, nil
@@ -275,14 +594,14 @@ func (a App) updateKeyBinds(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "q":
 			_ = a.keys.Save()
 			a.screen = ScreenMenu
-			a.menu = NewMenuModel(a.styles)
+			a.menu = a.newMenu()
 		case "esc":
 			if a.keyBinds.listening {
 				a.keyBinds.listening = false
 			} else {
 				_ = a.keys.Save()
 				a.screen = ScreenMenu
-				a.menu = NewMenuModel(a.styles)
+				a.menu = a.newMenu()
 			}
 		default:
 			a.keyBinds = a.keyBinds.Update(msg, a.keys)
@@ -290,3 +609,164 @@ func (a App) updateKeyBinds(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 	return a, nil
 }
+
+func (a App) updateReplays(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "q":
+			a.screen = ScreenMenu
+			a.menu = a.newMenu()
+		case "enter", "l":
+			entry, ok := a.replays.Selected()
+			if !ok {
+				return a, nil
+			}
+			r, err := replay.Load(entry.Path)
+			if err != nil {
+				return a, nil
+			}
+			a.playback = NewReplayModel(r)
+			a.screen = ScreenReplay
+			return a, a.playback.Init()
+		default:
+			a.replays = a.replays.Update(msg)
+		}
+	}
+	return a, nil
+}
+
+func (a App) updateReplay(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	a.playback, cmd = a.playback.Update(msg, a.rainbow)
+	if a.playback.exit {
+		if a.quitAfterReplay {
+			return a, tea.Quit
+		}
+		a.screen = ScreenReplays
+		return a, nil
+	}
+	return a, cmd
+}
+
+func (a App) updateWatch(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "q", "esc":
+			_ = a.watch.watcher.Close()
+			return a, tea.Quit
+		}
+	}
+	var cmd tea.Cmd
+	a.watch, cmd = a.watch.Update(msg)
+	return a, cmd
+}
+
+// updateBot hands a bot's moves to the game or demo they are for, on
+// whatever screen is showing.
+func (a App) updateBot(msg BotMovesMsg) (tea.Model, tea.Cmd) {
+	if a.attract {
+		a.demo, _ = a.demo.Update(msg, a.rainbow)
+		return a, nil
+	}
+	a.game = a.game.receiveBot(msg)
+	if a.game.botErr != nil {
+		return a.abandonBot()
+	}
+	return a, nil
+}
+
+// abandonBot ends a versus match whose bot stopped playing.
+func (a App) abandonBot() (tea.Model, tea.Cmd) {
+	a.result = newAbandonedResult(a.game, a.match, "The bot stopped: "+a.game.botErr.Error())
+	a.screen = ScreenVersusResult
+	return a, nil
+}
+
+// startDemo starts the demo on the main menu.
+func (a App) startDemo() (tea.Model, tea.Cmd) {
+	a.demo = NewDemoModel(a.cfg, a.bot)
+	a.attract = true
+	return a, a.demo.Init()
+}
+
+// updateDemo plays the demo on the main menu until a key is pressed. The
+// key only stops the demo, so a player waking the menu doesn't select
+// anything by accident.
+func (a App) updateDemo(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if _, ok := msg.(tea.KeyMsg); ok {
+		a.attract = false
+		return a, nil
+	}
+	var cmd tea.Cmd
+	a.demo, cmd = a.demo.Update(msg, a.rainbow)
+	return a, cmd
+}
+
+// attractView puts the demo beside the menu, or shows the demo alone when
+// the terminal is too narrow for both.
+func (a App) attractView(menu string) string {
+	demo := a.demo.View(a.styles, a.cfg, a.rainbow)
+	if a.width < lipgloss.Width(menu)+attractGap+lipgloss.Width(demo) {
+		return demo
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Center, menu, strings.Repeat(" ", attractGap), demo)
+}
+
+// broadcastStatus tells the player their game is being broadcast.
+func (a App) broadcastStatus() string {
+	return lipgloss.NewStyle().Foreground(a.styles.Theme.SubAlt).Render(
+		fmt.Sprintf("● live on %s  %d watching", a.broadcast.Addr(), a.broadcast.Spectators()))
+}
+
+func (a App) updateModes(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "q":
+			_ = a.cfg.Save()
+			a.screen = ScreenMenu
+			a.menu = a.newMenu()
+		case "enter":
+			_ = a.cfg.Save()
+			a.mode = a.modes.Selected()
+			a.match = NewVersusMatch(a.cfg.VersusRounds)
+			a.game = a.newGame()
+			a.screen = ScreenGame
+			return a, a.game.Init()
+		default:
+			a.modes = a.modes.Update(msg, a.cfg)
+		}
+	}
+	return a, nil
+}
+
+func (a App) updateVersusResult(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "enter", "r":
+			// Online, only the host starts rounds.
+			if a.result.guest || a.result.disconnected != "" {
+				return a, nil
+			}
+			if a.result.MatchOver() {
+				if msg.String() != "r" {
+					return a, nil
+				}
+				a.match = NewVersusMatch(a.match.BestOf)
+			}
+			if a.session != nil {
+				return a.startNetRound()
+			}
+			a.game = a.newGame()
+			a.screen = ScreenGame
+			return a, a.game.Init()
+		case "q", "esc":
+			a.leaveNetplay()
+			a.screen = ScreenMenu
+			a.menu = a.newMenu()
+		}
+	}
+	return a, nil
+}
@@ -0,0 +1,122 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/meszmate/briks/internal/ai"
+	"github.com/meszmate/briks/internal/config"
+	"github.com/meszmate/briks/internal/game"
+	"github.com/meszmate/briks/internal/tbp"
+)
+
+// botInputInterval is how long a bot waits between inputs, so that its
+// moves can be followed.
+const botInputInterval = 50 * time.Millisecond
+
+// versusBotDelay is how long a versus bot waits before each piece, which
+// keeps it beatable.
+const versusBotDelay = 300 * time.Millisecond
+
+// autopilot plays a game with a bot at a steady pace. The built-in AI
+// plans each piece at once; a TBP bot is asked on its own goroutine and
+// answers with a BotMovesMsg.
+type autopilot struct {
+	ai  *ai.Bot
+	tbp *tbp.Driver
+	// pieceDelay is the pause before each piece.
+	pieceDelay time.Duration
+
+	// plan is what is left of the inputs for the current piece, which
+	// was the pieces-th piece of the game.
+	plan     []config.Action
+	pieces   int
+	thinking bool
+	// wait is the time left until the next input.
+	wait time.Duration
+}
+
+// newAutopilot creates an autopilot for a new game, played by the TBP
+// bot if there is one and by the built-in AI otherwise.
+func newAutopilot(bot *tbp.Client, pieceDelay time.Duration) *autopilot {
+	p := &autopilot{pieceDelay: pieceDelay, pieces: -1, wait: pieceDelay}
+	if bot != nil {
+		p.tbp = tbp.NewDriver(bot)
+	} else {
+		p.ai = ai.New(nil)
+	}
+	return p
+}
+
+// name returns the name of the bot.
+func (p *autopilot) name() string {
+	if p.tbp != nil {
+		return p.tbp.Name()
+	}
+	return tbp.BotName
+}
+
+// update lets dt pass and makes the inputs that fell due with do. It
+// returns the command asking a TBP bot for moves when a new piece needs
+// them.
+func (p *autopilot) update(dt time.Duration, e *game.Engine, do func(config.Action) bool) tea.Cmd {
+	p.wait -= dt
+	for p.wait <= 0 && !p.thinking && e.State == game.StatePlaying {
+		if p.pieces != e.PiecesPlaced {
+			p.pieces = e.PiecesPlaced
+			if p.tbp != nil {
+				p.thinking = true
+				return p.ask(e)
+			}
+			placement, ok := p.ai.Best(ai.StateOf(e))
+			if !ok {
+				break
+			}
+			p.plan = placement.Actions()
+		}
+		if len(p.plan) == 0 {
+			break
+		}
+
+		action := p.plan[0]
+		p.plan = p.plan[1:]
+		p.wait += botInputInterval
+		if !do(action) && action != config.ActionHardDrop {
+			// Gravity got in the way: plan again from where the piece is.
+			p.pieces = -1
+		}
+		if e.PiecesPlaced != p.pieces && p.pieces >= 0 {
+			p.wait += p.pieceDelay
+		}
+	}
+	// Time spent waiting for moves doesn't pile up into a burst of inputs.
+	p.wait = max(p.wait, 0)
+	return nil
+}
+
+// ask requests moves for the engine's current piece from the TBP bot.
+func (p *autopilot) ask(e *game.Engine) tea.Cmd {
+	req := p.tbp.Suggest(e)
+	return func() tea.Msg {
+		moves, err := req()
+		return BotMovesMsg{pilot: p, Moves: moves, Err: err}
+	}
+}
+
+// receive takes the moves a TBP bot answered with.
+func (p *autopilot) receive(msg BotMovesMsg, e *game.Engine) error {
+	p.thinking = false
+	if msg.Err != nil {
+		return msg.Err
+	}
+	actions, err := p.tbp.Play(e, msg.Moves)
+	if err != nil {
+		return err
+	}
+	p.plan = actions
+	if actions == nil {
+		// The piece locked while the bot was thinking.
+		p.pieces = -1
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package tui
+
+import "time"
+
+// ShiftDir is a horizontal auto-shift direction.
+type ShiftDir int
+
+const (
+	ShiftNone ShiftDir = iota
+	ShiftLeft
+	ShiftRight
+)
+
+// Timings used to infer key state when the terminal cannot report key
+// releases and only delivers its own auto-repeat.
+const (
+	// repeatConfirm is the longest interval between two presses of the same
+	// key that is still taken to be terminal auto-repeat rather than taps.
+	repeatConfirm = 100 * time.Millisecond
+	// repeatGap is how long a held key may go without a repeat before it
+	// is considered released.
+	repeatGap = 150 * time.Millisecond
+	// repeatDelay is how long a key that was never seen repeating is kept
+	// around waiting for the terminal's initial repeat delay to pass.
+	repeatDelay = 700 * time.Millisecond
+)
+
+// shiftKey tracks one direction key.
+type shiftKey struct {
+	down      bool
+	confirmed bool // known to be held, not just tapped
+	lastSeen  time.Duration
+}
+
+// AutoShift implements DAS (delayed auto shift) and ARR (auto repeat rate)
+// for horizontal movement.
+//
+// When the terminal reports key releases the state machine simply follows
+// press/release. Otherwise a key only counts as held once the terminal's own
+// auto-repeat starts delivering it in quick succession, and it is released
+// once those repeats stop for longer than repeatGap. A quick tap therefore
+// never auto-shifts, at the cost of DAS never firing earlier than the
+// terminal's repeat delay.
+type AutoShift struct {
+	das time.Duration
+	arr time.Duration
+
+	releaseEvents bool
+	now           time.Duration
+	keys          [3]shiftKey
+
+	active ShiftDir
+	charge time.Duration // how long the active direction has been charging
+	repeat time.Duration // time accumulated towards the next ARR step
+	primed bool          // DAS has fully charged for the active direction
+}
+
+// NewAutoShift creates an auto-shift state machine with the given timings.
+func NewAutoShift(das, arr time.Duration) *AutoShift {
+	return &AutoShift{das: das, arr: arr}
+}
+
+// Press registers a key press or terminal repeat for a direction.
+// Returns true if the press is a fresh one that should move the piece once.
+func (s *AutoShift) Press(dir ShiftDir) bool {
+	k := &s.keys[dir]
+	if k.down {
+		if k.confirmed || s.now-k.lastSeen <= repeatConfirm {
+			// Terminal auto-repeat of a held key.
+			k.confirmed = true
+			k.lastSeen = s.now
+			return false
+		}
+		// Either another tap or the terminal's first repeat. Move once
+		// either way but keep charging from the original press.
+		k.lastSeen = s.now
+		if s.active != dir {
+			s.activate(dir)
+		}
+		return true
+	}
+
+	*k = shiftKey{down: true, confirmed: s.releaseEvents, lastSeen: s.now}
+
+	// The most recently pressed direction wins.
+	s.activate(dir)
+	return true
+}
+
+// Release registers a key release for a direction. Receiving one switches
+// the state machine to trust release events from then on.
+func (s *AutoShift) Release(dir ShiftDir) {
+	s.releaseEvents = true
+	s.release(dir)
+}
+
+// Reset forgets all held keys, e.g. when the game is paused.
+func (s *AutoShift) Reset() {
+	s.keys = [3]shiftKey{}
+	s.active = ShiftNone
+}
+
+// Update advances the state machine by dt and calls move for each
+// auto-shift step. With an ARR of zero the piece moves until move reports
+// that it is blocked.
+func (s *AutoShift) Update(dt time.Duration, move func(ShiftDir) bool) {
+	s.now += dt
+
+	if !s.releaseEvents {
+		for dir := ShiftLeft; dir <= ShiftRight; dir++ {
+			k := s.keys[dir]
+			if !k.down {
+				continue
+			}
+			if (k.confirmed && s.now-k.lastSeen > repeatGap) || s.now-k.lastSeen > repeatDelay {
+				s.release(dir)
+			}
+		}
+	}
+
+	if s.active == ShiftNone {
+		return
+	}
+
+	s.charge += dt
+	if !s.keys[s.active].confirmed || s.charge < s.das {
+		return
+	}
+
+	if !s.primed {
+		// DAS just charged: shift once immediately, carrying over the excess.
+		s.primed = true
+		s.repeat = s.charge - s.das
+		if !move(s.active) {
+			return
+		}
+	} else {
+		s.repeat += dt
+	}
+
+	if s.arr == 0 {
+		for move(s.active) {
+		}
+		return
+	}
+	for s.repeat >= s.arr {
+		s.repeat -= s.arr
+		if !move(s.active) {
+			s.repeat = 0
+			break
+		}
+	}
+}
+
+func (s *AutoShift) release(dir ShiftDir) {
+	s.keys[dir] = shiftKey{}
+	if s.active != dir {
+		return
+	}
+	s.active = ShiftNone
+
+	// Fall back to the other direction if it is still held; it has to
+	// charge DAS again from scratch.
+	other := ShiftLeft
+	if dir == ShiftLeft {
+		other = ShiftRight
+	}
+	if s.keys[other].down {
+		s.activate(other)
+	}
+}
+
+func (s *AutoShift) activate(dir ShiftDir) {
+	s.active = dir
+	s.charge = 0
+	s.repeat = 0
+	s.primed = false
+}
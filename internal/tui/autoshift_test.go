@@ -0,0 +1,139 @@
+package tui
+
+import (
+	"testing"
+	"time"
+)
+
+const (
+	testDAS = 100 * time.Millisecond
+	testARR = 20 * time.Millisecond
+	frame   = time.Millisecond
+)
+
+// shifts counts the auto-shift steps in each direction.
+type shifts map[ShiftDir]int
+
+// run advances s by d in frames, counting the steps. wall, if positive,
+// is how many steps fit before the piece is blocked.
+func run(s *AutoShift, d time.Duration, wall int) shifts {
+	got := shifts{}
+	for t := time.Duration(0); t < d; t += frame {
+		s.Update(frame, func(dir ShiftDir) bool {
+			if wall > 0 && got[dir] >= wall {
+				return false
+			}
+			got[dir]++
+			return true
+		})
+	}
+	return got
+}
+
+// withReleases returns an auto-shift on a terminal that reports key
+// releases.
+func withReleases(das, arr time.Duration) *AutoShift {
+	s := NewAutoShift(das, arr)
+	s.Release(ShiftNone)
+	return s
+}
+
+func TestAutoShiftCharge(t *testing.T) {
+	s := withReleases(testDAS, testARR)
+	if !s.Press(ShiftLeft) {
+		t.Fatal("first press didn't move")
+	}
+	if got := run(s, testDAS-frame, 0); got[ShiftLeft] != 0 {
+		t.Errorf("%d steps before DAS charged", got[ShiftLeft])
+	}
+	if got := run(s, frame, 0); got[ShiftLeft] != 1 {
+		t.Errorf("%d steps when DAS charged, want 1", got[ShiftLeft])
+	}
+}
+
+func TestAutoShiftRepeat(t *testing.T) {
+	s := withReleases(testDAS, testARR)
+	s.Press(ShiftRight)
+	run(s, testDAS, 0)
+	if got := run(s, 5*testARR, 0); got[ShiftRight] != 5 {
+		t.Errorf("%d steps in 5 ARR intervals, want 5", got[ShiftRight])
+	}
+	if got := run(s, testARR/2, 0); got[ShiftRight] != 0 {
+		t.Errorf("%d steps in half an ARR interval", got[ShiftRight])
+	}
+}
+
+func TestAutoShiftZeroARR(t *testing.T) {
+	s := withReleases(testDAS, 0)
+	s.Press(ShiftLeft)
+	if got := run(s, 2*testDAS, 4); got[ShiftLeft] != 4 {
+		t.Errorf("%d steps with zero ARR, want 4 to the wall", got[ShiftLeft])
+	}
+}
+
+func TestAutoShiftDirectionSwitch(t *testing.T) {
+	s := withReleases(testDAS, testARR)
+	s.Press(ShiftLeft)
+	run(s, testDAS, 0)
+
+	// The newer direction takes over and charges DAS from scratch.
+	if !s.Press(ShiftRight) {
+		t.Fatal("pressing the other direction didn't move")
+	}
+	got := run(s, testDAS-frame, 0)
+	if got[ShiftLeft] != 0 || got[ShiftRight] != 0 {
+		t.Errorf("steps %v while the new direction charged", got)
+	}
+	if got := run(s, frame, 0); got[ShiftRight] != 1 {
+		t.Errorf("%d right steps when DAS charged, want 1", got[ShiftRight])
+	}
+
+	// Releasing it falls back to the direction still held, which charges
+	// again.
+	s.Release(ShiftRight)
+	if got := run(s, testDAS-frame, 0); got[ShiftLeft] != 0 || got[ShiftRight] != 0 {
+		t.Errorf("steps %v while falling back", got)
+	}
+	if got := run(s, frame, 0); got[ShiftLeft] != 1 {
+		t.Errorf("%d left steps after falling back, want 1", got[ShiftLeft])
+	}
+}
+
+func TestAutoShiftRelease(t *testing.T) {
+	s := withReleases(testDAS, testARR)
+	s.Press(ShiftLeft)
+	run(s, testDAS+testARR, 0)
+	s.Release(ShiftLeft)
+	if got := run(s, time.Second, 0); got[ShiftLeft] != 0 {
+		t.Errorf("%d steps after release", got[ShiftLeft])
+	}
+	if !s.Press(ShiftLeft) {
+		t.Error("pressing again after release didn't move")
+	}
+}
+
+func TestAutoShiftTerminalRepeat(t *testing.T) {
+	// Without release events a single tap never auto-shifts.
+	s := NewAutoShift(testDAS, testARR)
+	s.Press(ShiftLeft)
+	if got := run(s, time.Second, 0); got[ShiftLeft] != 0 {
+		t.Errorf("%d steps after a tap", got[ShiftLeft])
+	}
+
+	// The terminal's own repeats confirm a held key, which then shifts
+	// until they stop.
+	s = NewAutoShift(testDAS, testARR)
+	s.Press(ShiftLeft)
+	total := 0
+	for range 10 {
+		total += run(s, 30*time.Millisecond, 0)[ShiftLeft]
+		s.Press(ShiftLeft)
+	}
+	if total == 0 {
+		t.Error("a repeating key never shifted")
+	}
+	run(s, repeatGap+frame, 0)
+	if got := run(s, time.Second, 0); got[ShiftLeft] != 0 {
+		t.Errorf("%d steps after the repeats stopped", got[ShiftLeft])
+	}
+}
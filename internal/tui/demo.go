@@ -0,0 +1,126 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/meszmate/briks/internal/config"
+	"github.com/meszmate/briks/internal/game"
+	"github.com/meszmate/briks/internal/replay"
+	"github.com/meszmate/briks/internal/tbp"
+	"github.com/meszmate/briks/internal/theme"
+)
+
+const (
+	// demoRestartDelay is how long a finished demo game stays on screen
+	// before the next one starts.
+	demoRestartDelay = 2 * time.Second
+	// demoIdleDelay is how long the main menu waits for a key before it
+	// starts the demo.
+	demoIdleDelay = 30 * time.Second
+	// idleCheckInterval is how often the menu checks whether it is idle.
+	idleCheckInterval = time.Second
+	// attractGap is the space between the menu and the demo beside it.
+	attractGap = 6
+)
+
+func idleTick() tea.Cmd {
+	return tea.Tick(idleCheckInterval, func(t time.Time) tea.Msg {
+		return IdleTickMsg{Time: t}
+	})
+}
+
+// DemoModel shows a bot playing Marathon games, one after another, on the
+// main menu.
+type DemoModel struct {
+	cfg    *config.Config
+	bot    *tbp.Client
+	engine *game.Engine
+	pilot  *autopilot
+	// err is why the bot stopped playing.
+	err error
+
+	// wait is the time left until the next game starts.
+	wait      time.Duration
+	loop      int64
+	lastFrame time.Time
+}
+
+// NewDemoModel creates a demo played by a TBP bot, or by the built-in AI
+// if bot is nil.
+func NewDemoModel(cfg *config.Config, bot *tbp.Client) DemoModel {
+	m := DemoModel{
+		cfg:  cfg,
+		bot:  bot,
+		loop: newFrameLoop(),
+	}
+	m.restart()
+	return m
+}
+
+func (m *DemoModel) restart() {
+	m.engine = game.NewEngine(game.MarathonOptions(m.cfg.StartLevel, m.cfg.PreviewCount, game.RandomSeed()))
+	m.pilot = newAutopilot(m.bot, 0)
+}
+
+// Init starts the demo frame loop.
+func (m DemoModel) Init() tea.Cmd {
+	return frameTick(m.loop)
+}
+
+// Update advances the demo game on frame ticks.
+func (m DemoModel) Update(msg tea.Msg, rainbow *theme.RainbowState) (DemoModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case BotMovesMsg:
+		if msg.pilot == m.pilot {
+			m.err = m.pilot.receive(msg, m.engine)
+		}
+		return m, nil
+
+	case FrameTickMsg:
+		if msg.Loop != m.loop {
+			return m, nil
+		}
+		var dt time.Duration
+		if !m.lastFrame.IsZero() {
+			dt = min(msg.Time.Sub(m.lastFrame), maxFrameDelta)
+		}
+		m.lastFrame = msg.Time
+		if rainbow != nil {
+			rainbow.Tick(dt.Seconds())
+		}
+		if m.err != nil {
+			return m, frameTick(m.loop)
+		}
+
+		if m.engine.Over() {
+			m.wait -= dt
+			if m.wait <= 0 {
+				m.restart()
+			}
+			return m, frameTick(m.loop)
+		}
+		m.engine.Advance(dt)
+		ask := m.pilot.update(dt, m.engine, func(a config.Action) bool {
+			return replay.Apply(m.engine, a)
+		})
+		if m.engine.Over() {
+			m.wait = demoRestartDelay
+		}
+		return m, tea.Batch(frameTick(m.loop), ask)
+	}
+	return m, nil
+}
+
+// View renders the demo game.
+func (m DemoModel) View(s Styles, cfg *config.Config, rainbow *theme.RainbowState) string {
+	t := s.Theme
+	status := lipgloss.NewStyle().Foreground(t.Main).Bold(true).Render("DEMO") +
+		lipgloss.NewStyle().Foreground(t.Sub).Render("  "+m.pilot.name())
+	if m.err != nil {
+		status += "\n" + lipgloss.NewStyle().Foreground(t.PieceZ).Render("The bot stopped: "+m.err.Error())
+	}
+	help := lipgloss.NewStyle().Foreground(t.SubAlt).Render("any key stops the demo")
+	return renderPlayfield(m.engine, s, cfg, rainbow, Overlay{}, status+"\n"+help)
+}
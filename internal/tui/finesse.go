@@ -0,0 +1,126 @@
+package tui
+
+import (
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/meszmate/briks/internal/game"
+)
+
+// finesseFlash is how long a piece placed with a finesse fault flashes,
+// and finesseBlink how fast.
+const (
+	finesseFlash = 400 * time.Millisecond
+	finesseBlink = 100 * time.Millisecond
+)
+
+// finesse counts a player's key presses per piece and judges each piece
+// against the fewest presses that would have placed it.
+type finesse struct {
+	stats game.FinesseStats
+	// presses counts the presses for the current piece.
+	presses int
+	// turn180 is set when the player has a key for 180° turns, which
+	// then count as one press.
+	turn180 bool
+
+	// flash is the cells of the last fault, flashing until flashUntil on
+	// the game clock.
+	flash      []game.Position
+	flashUntil time.Duration
+
+	// train, if set, runs the finesse trainer.
+	train *trainer
+}
+
+// trainer gives each piece of the finesse trainer a target to be placed
+// at, and scores the placements.
+type trainer struct {
+	rng    *rand.Rand
+	target game.Piece
+	// hits are pieces placed on target without a fault; misses the rest.
+	hits, misses int
+}
+
+func newFinesse(turn180 bool) *finesse {
+	return &finesse{turn180: turn180}
+}
+
+// newTrainer creates a trainer with a target on b for the first piece.
+func newTrainer(seed uint64, b *game.Board, first game.PieceType) *trainer {
+	t := &trainer{rng: rand.New(rand.NewPCG(seed, seed>>32))}
+	t.spawned(b, first)
+	return t
+}
+
+// observe follows the engine's events.
+func (f *finesse) observe(e *game.Engine, ev game.Event) {
+	switch ev := ev.(type) {
+	case game.PieceSpawned:
+		f.presses = 0
+		if f.train != nil {
+			f.train.spawned(e.Board, ev.Piece.Type)
+		}
+	case game.PieceLocked:
+		f.locked(e, ev.Piece)
+	}
+}
+
+// press counts a move or rotation key press for the current piece.
+func (f *finesse) press() {
+	f.presses++
+}
+
+// locked judges a piece locking where it is, on the board as it found it.
+func (f *finesse) locked(e *game.Engine, p game.Piece) {
+	need, judged := e.Board.FinessePresses(&p, f.turn180)
+	fault := judged && f.presses > need
+	if judged {
+		f.stats.Pieces++
+	}
+	if fault {
+		f.stats.Faults++
+		f.flash = e.Board.PieceCells(&p)
+		f.flashUntil = e.Clock + finesseFlash
+	}
+	if f.train != nil {
+		if !fault && sameCells(e.Board, p, f.train.target) {
+			f.train.hits++
+		} else {
+			f.train.misses++
+		}
+	}
+}
+
+// overlay returns the finesse information to draw on the playfield.
+func (f *finesse) overlay(e *game.Engine) Overlay {
+	o := Overlay{Finesse: &f.stats}
+	if left := f.flashUntil - e.Clock; left > 0 && (left/finesseBlink)%2 == 0 {
+		o.Flash = f.flash
+	}
+	if f.train != nil && e.Current != nil {
+		o.Outline = &f.train.target
+	}
+	return o
+}
+
+// spawned picks the target of a new piece on b.
+func (t *trainer) spawned(b *game.Board, pt game.PieceType) {
+	dests := b.Destinations(pt)
+	t.target = dests[t.rng.IntN(len(dests))]
+}
+
+// sameCells reports whether two pieces cover the same cells of a board.
+func sameCells(board *game.Board, a, b game.Piece) bool {
+	ac, bc := board.PieceCells(&a), board.PieceCells(&b)
+	cmp := func(x, y game.Position) int {
+		if x.Row != y.Row {
+			return x.Row - y.Row
+		}
+		return x.Col - y.Col
+	}
+	slices.SortFunc(ac, cmp)
+	slices.SortFunc(bc, cmp)
+	return slices.Equal(ac, bc)
+}
@@ -1,57 +1,251 @@
 package tui
 
 import (
+	"fmt"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/meszmate/briks/internal/config"
 	"github.com/meszmate/briks/internal/game"
+	"github.com/meszmate/briks/internal/netplay"
+	"github.com/meszmate/briks/internal/replay"
+	"github.com/meszmate/briks/internal/tbp"
 	"github.com/meszmate/briks/internal/theme"
 )
 
-// GameModel handles the gameplay screen.
+const (
+	// frameInterval is how often FrameTickMsg fires.
+	frameInterval = time.Second / 60
+	// maxFrameDelta caps how much game time a single frame may advance, so
+	// a stalled terminal or a suspended laptop doesn't drop pieces on wake.
+	maxFrameDelta = 250 * time.Millisecond
+)
+
+// frameLoops hands out frame loop ids. Each screen that runs a frame loop
+// takes a fresh id, so ticks still in flight from an earlier loop (e.g.
+// before a pause) are ignored instead of doubling the tick rate.
+var frameLoops atomic.Int64
+
+func newFrameLoop() int64 {
+	return frameLoops.Add(1)
+}
+
+func frameTick(loop int64) tea.Cmd {
+	return tea.Tick(frameInterval, func(t time.Time) tea.Msg {
+		return FrameTickMsg{Time: t, Loop: loop}
+	})
+}
+
+// player is one side of the game screen: an engine with its own controls,
+// or the mirror of a network opponent.
+type player struct {
+	name   string
+	engine *game.Engine
+	keys   *config.KeyBindings
+	shift  *AutoShift
+	// recorder records the game for a replay; nil when it isn't recorded.
+	recorder *replay.Recorder
+	// net streams the game to a network opponent; nil when offline.
+	net *netplay.Session
+	// remote marks a network opponent, whose engine is driven by the
+	// mirror rather than by the local clock and keyboard.
+	remote bool
+	// pilot plays the game for a bot opponent; nil for people.
+	pilot *autopilot
+	// sent is the garbage sent to the opponent this game.
+	sent int
+	// finesse judges the player's key presses; nil for bots and online
+	// opponents.
+	finesse *finesse
+}
+
+func newPlayer(cfg *config.Config, keys *config.KeyBindings, opts game.Options) *player {
+	p := &player{
+		engine: game.NewEngine(opts),
+		keys:   keys,
+		shift: NewAutoShift(
+			time.Duration(cfg.DAS)*time.Millisecond,
+			time.Duration(cfg.ARR)*time.Millisecond,
+		),
+		finesse: newFinesse(len(keys.GetKeys(config.ActionRotate180)) > 0),
+	}
+	p.engine.Subscribe(p.observe)
+	return p
+}
+
+// observe follows the events of the player's engine.
+func (p *player) observe(ev game.Event) {
+	if p.finesse != nil {
+		p.finesse.observe(p.engine, ev)
+	}
+}
+
+// do applies an action to the player's engine, recording it if the game
+// is recorded and streaming it if it is played online.
+func (p *player) do(action config.Action) bool {
+	t, state := p.engine.Clock, p.engine.State
+	var ok bool
+	if p.recorder != nil {
+		ok = p.recorder.Do(action)
+	} else {
+		ok = replay.Apply(p.engine, action)
+	}
+	if p.net != nil && (ok || p.engine.State != state) {
+		_ = p.net.Input(t, action)
+	}
+	return ok
+}
+
+// receiveGarbage queues incoming garbage.
+func (p *player) receiveGarbage(lines int) {
+	if lines <= 0 {
+		return
+	}
+	p.engine.ReceiveGarbage(lines)
+	if p.net != nil {
+		_ = p.net.Garbage(p.engine.Clock, lines)
+	}
+}
+
+// press counts a move or rotation key press towards the finesse of the
+// current piece.
+func (p *player) press() {
+	if p.finesse != nil {
+		p.finesse.press()
+	}
+}
+
+// overlay returns what to draw on the player's playfield besides the game.
+func (p *player) overlay() Overlay {
+	if p.finesse == nil {
+		return Overlay{}
+	}
+	return p.finesse.overlay(p.engine)
+}
+
+// shiftMove applies one auto-shift step to the engine.
+func (p *player) shiftMove(dir ShiftDir) bool {
+	if dir == ShiftLeft {
+		return p.do(config.ActionMoveLeft)
+	}
+	return p.do(config.ActionMoveRight)
+}
+
+// GameModel handles the gameplay screen for a single player or for two
+// players sharing the keyboard in versus.
 type GameModel struct {
-	engine   *game.Engine
-	keys     *config.KeyBindings
-	rainbow  *theme.RainbowState
-	paused   bool
-	gameOver bool
+	players   []*player
+	mirror    *netplay.Mirror
+	rainbow   *theme.RainbowState
+	loop      int64
+	lastFrame time.Time
+	paused    bool
+	gameOver  bool
+	// botErr is why a bot opponent stopped playing.
+	botErr error
+	// hints suggests placements; nil unless hints are on.
+	hints *hinter
+	// practice backs undo and save states; nil unless practice is on.
+	practice *practice
+	// wasPractice marks a continued game that was a practice game before
+	// it was suspended.
+	wasPractice bool
+}
+
+// NewGameModel creates a new single-player gameplay model.
+func NewGameModel(cfg *config.Config, keys *config.KeyBindings, rainbow *theme.RainbowState, opts game.Options) GameModel {
+	p := newPlayer(cfg, keys, opts)
+	p.recorder = replay.NewRecorder(p.engine, cfg)
+	g := GameModel{
+		players: []*player{p},
+		rainbow: rainbow,
+		loop:    newFrameLoop(),
+	}
+	switch {
+	case opts.Mode == game.ModeFinesse:
+		p.finesse.train = newTrainer(opts.Seed, p.engine.Board, p.engine.Current.Type)
+	case cfg.Hints:
+		g.hints = newHinter()
+	}
+	if cfg.Practice {
+		g.practice = newPractice(p.engine, cfg.UndoDepth)
+	}
+	return g
+}
+
+// NewVersusModel creates a versus round between two players with their
+// own key bindings. Both get the same options and so the same pieces.
+func NewVersusModel(cfg *config.Config, keys [2]*config.KeyBindings, rainbow *theme.RainbowState, opts game.Options) GameModel {
+	p1 := newPlayer(cfg, keys[0], opts)
+	p1.name = "P1"
+	p2 := newPlayer(cfg, keys[1], opts)
+	p2.name = "P2"
+	return GameModel{
+		players: []*player{p1, p2},
+		rainbow: rainbow,
+		loop:    newFrameLoop(),
+	}
+}
+
+// NewBotModel creates a versus round between the player and a TBP bot,
+// or the built-in AI if bot is nil.
+func NewBotModel(cfg *config.Config, keys *config.KeyBindings, rainbow *theme.RainbowState, opts game.Options, bot *tbp.Client) GameModel {
+	local := newPlayer(cfg, keys, opts)
+	local.name = "YOU"
+	opp := newPlayer(cfg, keys, opts)
+	opp.pilot = newAutopilot(bot, versusBotDelay)
+	opp.finesse = nil
+	// Short enough for a column of the result table.
+	name := []rune(strings.ToUpper(opp.pilot.name()))
+	opp.name = string(name[:min(len(name), 9)])
+	return GameModel{
+		players: []*player{local, opp},
+		rainbow: rainbow,
+		loop:    newFrameLoop(),
+	}
 }
 
-// NewGameModel creates a new gameplay model.
-func NewGameModel(cfg *config.Config, keys *config.KeyBindings, rainbow *theme.RainbowState) GameModel {
+// NewNetModel creates a network versus round: the local player against a
+// mirror of the opponent's game.
+func NewNetModel(cfg *config.Config, keys *config.KeyBindings, rainbow *theme.RainbowState, opts game.Options, session *netplay.Session) GameModel {
+	local := newPlayer(cfg, keys, opts)
+	local.name = "YOU"
+	local.net = session
+	mirror := netplay.NewMirror(opts)
 	return GameModel{
-		engine:  game.NewEngine(cfg.StartLevel, cfg.PreviewCount),
-		keys:    keys,
+		players: []*player{local, {name: "OPP", engine: mirror.Engine, remote: true}},
+		mirror:  mirror,
 		rainbow: rainbow,
+		loop:    newFrameLoop(),
 	}
 }
 
+// engine returns the first player's engine, the only one outside versus.
+func (g GameModel) engine() *game.Engine {
+	return g.players[0].engine
+}
+
+// versus reports whether this is a two-player game.
+func (g GameModel) versus() bool {
+	return len(g.players) == 2
+}
+
+// session returns the network session of an online game, or nil.
+func (g GameModel) session() *netplay.Session {
+	return g.players[0].net
+}
+
 // Init returns the initial commands for the game.
 func (g GameModel) Init() tea.Cmd {
 	return tea.Batch(
-		g.gravityTick(),
-		g.lockTick(),
+		frameTick(g.loop),
 		g.rainbowTick(),
 	)
 }
 
-func (g GameModel) gravityTick() tea.Cmd {
-	interval := g.engine.Scorer.GravityInterval()
-	d := time.Duration(float64(time.Second) * interval)
-	return tea.Tick(d, func(t time.Time) tea.Msg {
-		return TickMsg{Time: t}
-	})
-}
-
-func (g GameModel) lockTick() tea.Cmd {
-	return tea.Tick(50*time.Millisecond, func(t time.Time) tea.Msg {
-		return LockTickMsg{Time: t}
-	})
-}
-
 func (g GameModel) rainbowTick() tea.Cmd {
 	return tea.Tick(50*time.Millisecond, func(t time.Time) tea.Msg {
 		return RainbowTickMsg{Time: t}
@@ -60,38 +254,140 @@ func (g GameModel) rainbowTick() tea.Cmd {
 
 func (g GameModel) resumeTick() tea.Cmd {
 	return tea.Batch(
-		g.gravityTick(),
-		g.lockTick(),
+		frameTick(g.loop),
 		g.rainbowTick(),
 	)
 }
 
+// resume clears state that must not carry over a pause.
+func (g *GameModel) resume() {
+	g.paused = false
+	g.loop = newFrameLoop()
+	g.lastFrame = time.Time{}
+	for _, p := range g.players {
+		if !p.remote {
+			p.shift.Reset()
+		}
+	}
+}
+
+// hinted reports whether placement hints were shown during the game.
+func (g GameModel) hinted() bool {
+	return g.hints != nil
+}
+
+// finesse returns the first player's finesse so far.
+func (g GameModel) finesse() game.FinesseStats {
+	if f := g.players[0].finesse; f != nil {
+		return f.stats
+	}
+	return game.FinesseStats{}
+}
+
+// practiced reports whether pieces were undone or states loaded during
+// the game.
+func (g GameModel) practiced() bool {
+	return g.wasPractice || g.practice != nil && g.practice.used
+}
+
+// saveReplay stores the recording of the finished game with the rest of
+// the player's data in dataDir.
+func (g GameModel) saveReplay(dataDir string) {
+	if r := g.players[0].recorder; r != nil {
+		_, _ = replay.Save(dataDir, r.Finish())
+	}
+}
+
+// settle passes garbage between versus players and reports whether the
+// game has ended: when the engine is over alone, or when either player
+// has topped out in local versus. Online, only the local player's end is
+// reported; the host decides the round.
+func (g GameModel) settle() bool {
+	if !g.versus() {
+		return g.engine().Over()
+	}
+	for i, p := range g.players {
+		out := p.engine.TakeOutgoing()
+		p.sent += out
+		switch {
+		case p.net != nil:
+			if out > 0 {
+				_ = p.net.Attack(out)
+			}
+		case !p.remote && g.session() == nil:
+			g.players[1-i].receiveGarbage(out)
+		}
+	}
+	if g.session() != nil {
+		return g.engine().Over()
+	}
+	return g.players[0].engine.Over() || g.players[1].engine.Over()
+}
+
+// winner returns the index of the versus player left standing, or -1 if
+// both topped out together.
+func (g GameModel) winner() int {
+	switch {
+	case !g.players[0].engine.Over():
+		return 0
+	case !g.players[1].engine.Over():
+		return 1
+	default:
+		return -1
+	}
+}
+
 // Update processes messages for the game screen.
-func (g GameModel) Update(msg tea.Msg, keys *config.KeyBindings) (GameModel, tea.Cmd) {
+func (g GameModel) Update(msg tea.Msg) (GameModel, tea.Cmd) {
 	if g.paused || g.gameOver {
 		return g, nil
 	}
 
 	switch msg := msg.(type) {
-	case TickMsg:
-		if g.engine.State == game.StatePlaying {
-			g.engine.Tick()
-			if g.engine.State == game.StateGameOver {
-				g.gameOver = true
-				return g, nil
+	case FrameTickMsg:
+		if msg.Loop != g.loop {
+			return g, nil
+		}
+		// Real time is fed into the engines frame by frame. The first
+		// frame after starting or resuming advances nothing, so paused
+		// time is never counted.
+		var dt time.Duration
+		if !g.lastFrame.IsZero() {
+			dt = min(msg.Time.Sub(g.lastFrame), maxFrameDelta)
+		}
+		g.lastFrame = msg.Time
+		cmds := []tea.Cmd{frameTick(g.loop)}
+		for _, p := range g.players {
+			if !p.remote && p.engine.State == game.StatePlaying {
+				p.shift.Update(dt, p.shiftMove)
+				p.engine.Advance(dt)
+				if p.net != nil {
+					_ = p.net.Clock(p.engine.Clock)
+				}
+				if p.pilot != nil {
+					cmds = append(cmds, p.pilot.update(dt, p.engine, p.do))
+				}
 			}
 		}
-		return g, g.gravityTick()
+		if g.practice != nil {
+			g.practice.track(g.engine())
+		}
+		if g.settle() {
+			g.gameOver = true
+			return g, nil
+		}
+		return g, tea.Batch(cmds...)
 
-	case LockTickMsg:
-		if g.engine.State == game.StatePlaying {
-			g.engine.CheckLock()
-			if g.engine.State == game.StateGameOver {
-				g.gameOver = true
-				return g, nil
+	case KeyReleaseMsg:
+		if p, action, ok := g.matchKey(msg.Key); ok {
+			switch action {
+			case config.ActionMoveLeft:
+				p.shift.Release(ShiftLeft)
+			case config.ActionMoveRight:
+				p.shift.Release(ShiftRight)
 			}
 		}
-		return g, g.lockTick()
+		return g, nil
 
 	case RainbowTickMsg:
 		if g.rainbow != nil {
@@ -100,59 +396,206 @@ func (g GameModel) Update(msg tea.Msg, keys *config.KeyBindings) (GameModel, tea
 		return g, g.rainbowTick()
 
 	case tea.KeyMsg:
-		return g.handleKey(msg, keys)
+		return g.handleKey(msg)
 	}
 
 	return g, nil
 }
 
-func (g GameModel) handleKey(msg tea.KeyMsg, keys *config.KeyBindings) (GameModel, tea.Cmd) {
-	key := msg.String()
+// receiveBot hands moves to the bot player they are for. Moves for a
+// game that has ended are dropped.
+func (g GameModel) receiveBot(msg BotMovesMsg) GameModel {
+	for _, p := range g.players {
+		if p.pilot == msg.pilot {
+			if err := p.pilot.receive(msg, p.engine); err != nil {
+				g.botErr = err
+			}
+		}
+	}
+	return g
+}
+
+// matchKey finds the player a key belongs to and its action. Players are
+// tried in order, so the first player wins a key bound by both.
+func (g GameModel) matchKey(key string) (*player, config.Action, bool) {
+	for _, p := range g.players {
+		if p.remote || p.pilot != nil {
+			continue
+		}
+		if action, ok := p.keys.MatchAction(key); ok {
+			return p, action, true
+		}
+	}
+	return nil, "", false
+}
 
-	action, ok := keys.MatchAction(key)
+func (g GameModel) handleKey(msg tea.KeyMsg) (GameModel, tea.Cmd) {
+	p, action, ok := g.matchKey(msg.String())
 	if !ok {
 		return g, nil
 	}
 
 	switch action {
 	case config.ActionMoveLeft:
-		g.engine.MoveLeft()
-	case config.ActionMoveRight:
-		g.engine.MoveRight()
-	case config.ActionSoftDrop:
-		g.engine.SoftDrop()
-	case config.ActionHardDrop:
-		g.engine.HardDrop()
-		if g.engine.State == game.StateGameOver {
-			g.gameOver = true
-			return g, nil
+		if p.shift.Press(ShiftLeft) {
+			p.press()
+			p.do(action)
 		}
-	case config.ActionRotateCW:
-		g.engine.RotateCW()
-	case config.ActionRotateCCW:
-		g.engine.RotateCCW()
-	case config.ActionHold:
-		g.engine.Hold()
-		if g.engine.State == game.StateGameOver {
-			g.gameOver = true
-			return g, nil
+	case config.ActionMoveRight:
+		if p.shift.Press(ShiftRight) {
+			p.press()
+			p.do(action)
 		}
 	case config.ActionPause:
-		g.paused = true
+		// An online game can't be paused.
+		if g.session() == nil {
+			g.paused = true
+		}
 		return g, nil
+	case config.ActionRotateCW, config.ActionRotateCCW, config.ActionRotate180:
+		p.press()
+		p.do(action)
+	case config.ActionUndo, config.ActionSaveState, config.ActionLoadState:
+		if g.practice != nil {
+			g.practiceAction(p, action)
+		}
+		return g, nil
+	default:
+		p.do(action)
+	}
+	if g.practice != nil {
+		g.practice.track(g.engine())
 	}
 
+	if g.settle() {
+		g.gameOver = true
+	}
 	return g, nil
 }
 
+// practiceAction carries out a practice action. Once the game has been
+// changed by one it is no longer recorded, as its replay could not be
+// played back.
+func (g GameModel) practiceAction(p *player, action config.Action) {
+	e := p.engine
+	var changed bool
+	switch action {
+	case config.ActionSaveState:
+		g.practice.save(e)
+	case config.ActionUndo:
+		changed = g.practice.undo(e)
+	case config.ActionLoadState:
+		changed = g.practice.load(e)
+	}
+	if !changed {
+		return
+	}
+	p.recorder = nil
+	p.shift.Reset()
+	if p.finesse != nil {
+		p.finesse.presses = 0
+		if t := p.finesse.train; t != nil && e.Current != nil {
+			t.spawned(e.Board, e.Current.Type)
+		}
+	}
+}
+
 // View renders the gameplay screen.
 func (g GameModel) View(s Styles, cfg *config.Config, rainbow *theme.RainbowState) string {
-	t := s.Theme
+	dim := lipgloss.NewStyle().Foreground(s.Theme.SubAlt)
+	if !g.versus() {
+		help := dim.Render("h/l move  j drop  k rotate  c hold  space hard drop  p pause")
+		overlay := g.players[0].overlay()
+		accent := lipgloss.NewStyle().Foreground(s.Theme.Main)
+		if g.hints != nil {
+			if p, ok := g.hints.suggest(g.engine()); ok {
+				overlay.Outline = &p.Piece
+				if p.Hold {
+					help = accent.Render("hint: hold first") + "\n" + help
+				}
+			}
+		}
+		if g.practice != nil {
+			help = accent.Render(practiceHelp(g.players[0].keys, len(g.practice.history), g.practice.saved != nil)) + "\n" + help
+		}
+		if t := g.players[0].finesse.train; t != nil {
+			help = accent.Render(fmt.Sprintf("place each piece on its target  hits %d  misses %d", t.hits, t.misses)) + "\n" + help
+		}
+		return renderPlayfield(g.engine(), s, cfg, rainbow, overlay, help)
+	}
 
-	board := RenderBoard(g.engine, s, cfg.GhostPiece, cfg.ShowGrid, rainbow)
-	hold := RenderHoldPanel(g.engine.HoldPiece, g.engine.HoldUsed, s, rainbow)
-	next := RenderNextPanel(g.engine.NextPieces(), s, rainbow)
-	stats := RenderStatsPanel(g.engine.Scorer, s)
+	// Versus: the two playfields side by side, each labelled with its
+	// player and controls.
+	fields := make([]string, len(g.players))
+	for i, p := range g.players {
+		label := lipgloss.NewStyle().
+			Foreground(s.Theme.Main).
+			Bold(true).
+			Render(p.name)
+		help := "online opponent"
+		switch {
+		case p.pilot != nil:
+			help = "bot"
+		case !p.remote:
+			help = controlsHelp(p.keys)
+		}
+		fields[i] = renderPlayfield(p.engine, s, cfg, rainbow, p.overlay(), label+"\n"+dim.Render(help))
+	}
+	footer := "esc pause"
+	if g.session() != nil {
+		footer = "ctrl+c quit"
+		if g.gameOver {
+			footer = "waiting for the host…"
+		}
+	}
+	return lipgloss.JoinVertical(lipgloss.Center,
+		lipgloss.JoinHorizontal(lipgloss.Top, fields[0], "    ", fields[1]),
+		"",
+		dim.Render(footer),
+	)
+}
+
+// practiceHelp describes the practice keys, with the pieces that can be
+// undone and whether a state is saved.
+func practiceHelp(kb *config.KeyBindings, undos int, saved bool) string {
+	key := func(a config.Action) string { return boundKey(kb, a) }
+	state := "empty"
+	if saved {
+		state = "saved"
+	}
+	return fmt.Sprintf("practice  %s undo (%d)  %s save  %s load (%s)",
+		key(config.ActionUndo), undos,
+		key(config.ActionSaveState),
+		key(config.ActionLoadState), state)
+}
+
+// controlsHelp summarizes a player's key bindings in one line.
+func controlsHelp(kb *config.KeyBindings) string {
+	key := func(a config.Action) string { return boundKey(kb, a) }
+	return fmt.Sprintf("%s/%s move  %s drop  %s/%s rotate  %s hold  %s hard",
+		key(config.ActionMoveLeft), key(config.ActionMoveRight),
+		key(config.ActionSoftDrop),
+		key(config.ActionRotateCW), key(config.ActionRotateCCW),
+		key(config.ActionHold),
+		key(config.ActionHardDrop))
+}
+
+// boundKey shows the first key bound to an action, or "-" if none is.
+func boundKey(kb *config.KeyBindings, a config.Action) string {
+	keys := kb.GetKeys(a)
+	if len(keys) == 0 {
+		return "-"
+	}
+	return config.KeyDisplay(keys[0])
+}
+
+// renderPlayfield lays out the board, hold, next and stats panels of an
+// engine with a footer line below, drawing the overlay on them.
+func renderPlayfield(engine *game.Engine, s Styles, cfg *config.Config, rainbow *theme.RainbowState, overlay Overlay, footer string) string {
+	board := RenderBoard(engine, s, cfg.GhostPiece, cfg.ShowGrid, overlay, rainbow)
+	hold := RenderHoldPanel(engine.HoldPiece, engine.HoldUsed, engine.Board.System, s, rainbow)
+	next := RenderNextPanel(engine.NextPieces(), engine.Board.System, s, rainbow)
+	stats := RenderStatsPanel(engine, s, overlay.Finesse)
 
 	// Build left panel
 	var leftSb strings.Builder
@@ -165,22 +608,23 @@ func (g GameModel) View(s Styles, cfg *config.Config, rainbow *theme.RainbowStat
 		Render(leftSb.String())
 
 	// Build right panel
+	right := next
+	if callout := RenderCallout(engine, s); callout != "" {
+		right += "\n\n" + callout
+	}
 	rightPanel := lipgloss.NewStyle().
 		Width(12).
-		Render(next)
-
-	// Build help text
-	helpStyle := lipgloss.NewStyle().Foreground(t.SubAlt)
-	help := helpStyle.Render("h/l move  j drop  k rotate  c hold  space hard drop  p pause")
+		Render(right)
 
 	// Combine panels
 	gameRow := lipgloss.JoinHorizontal(lipgloss.Top,
 		leftPanel,
-		"  ",
+		" ",
+		RenderGarbageMeter(engine.PendingGarbage(), engine.Board.Height, s),
 		board,
 		"  ",
 		rightPanel,
 	)
 
-	return lipgloss.JoinVertical(lipgloss.Center, gameRow, "", help)
+	return lipgloss.JoinVertical(lipgloss.Center, gameRow, "", footer)
 }
@@ -12,72 +12,175 @@ import (
 
 // GameOverModel represents the game over screen.
 type GameOverModel struct {
-	score   int
-	level   int
-	lines   int
-	pieces  int
-	rank    int
-	isNewHS bool
+	mode     game.Mode
+	finished bool // the mode's goal was reached
+	lineGoal int
+	garbage  int
+	garbGoal int
+	score    int
+	level    int
+	lines    int
+	pieces   int
+	elapsed  time.Duration
+	seed     uint64
+	rank     int
+	isNewHS  bool
+	hinted   bool // placement hints were shown
+	practice bool // pieces were undone or states loaded
+	finesse  game.FinesseStats
 }
 
-// NewGameOverModel creates a game over model and saves the score.
-func NewGameOverModel(engine *game.Engine, hs *config.HighScores) GameOverModel {
+// NewGameOverModel creates a game over model and saves the result to the
+// leaderboard of its mode. Hinted results are flagged and rank below the
+// others; practice results are listed below those but never ranked.
+func NewGameOverModel(engine *game.Engine, hs *config.HighScores, bt *config.BestTimes, player string, hinted, practice bool, finesse game.FinesseStats) GameOverModel {
 	m := GameOverModel{
-		score:  engine.Scorer.Score,
-		level:  engine.Scorer.Level,
-		lines:  engine.Scorer.Lines,
-		pieces: engine.PiecesPlaced,
+		mode:     engine.Mode,
+		finished: engine.State == game.StateFinished,
+		lineGoal: engine.LineGoal,
+		garbage:  engine.GarbageCleared(),
+		garbGoal: engine.GarbageGoal,
+		score:    engine.Scorer.Score,
+		level:    engine.Scorer.Level,
+		lines:    engine.Scorer.Lines,
+		pieces:   engine.PiecesPlaced,
+		elapsed:  engine.ElapsedTime(),
+		seed:     engine.Seed,
+		hinted:   hinted,
+		practice: practice,
+		finesse:  finesse,
 	}
 
-	m.isNewHS = hs.IsHighScore(m.score)
-	if m.isNewHS {
+	switch m.mode {
+	case game.ModeSprint, game.ModeCheese:
+		// Only completed runs have a time worth ranking.
+		board := config.SprintBoard(m.lineGoal)
+		if m.mode == game.ModeCheese {
+			board = config.CheeseBoard(m.garbGoal)
+		}
+		board = config.VariantBoard(board, engine.Board.Width, engine.Board.Height, engine.Board.System.Name())
+		entry := config.BestTime{
+			Time:     m.elapsed,
+			Pieces:   m.pieces,
+			Seed:     m.seed,
+			Date:     time.Now(),
+			Name:     player,
+			Hinted:   hinted,
+			Practice: practice,
+		}
+		if m.finished && bt.IsRanked(board, entry) {
+			m.isNewHS = !practice
+			m.rank = bt.Add(board, entry)
+			_ = bt.Save()
+		}
+	default:
+		// Ultra ranks its score per time limit; a top out still counts.
+		var board string
+		if m.mode == game.ModeUltra {
+			board = config.UltraBoard(int(engine.TimeLimit / time.Second))
+		}
+		board = config.VariantBoard(board, engine.Board.Width, engine.Board.Height, engine.Board.System.Name())
 		entry := config.HighScore{
-			Score:  m.score,
-			Level:  m.level,
-			Lines:  m.lines,
-			Pieces: m.pieces,
-			Date:   time.Now(),
+			Score:    m.score,
+			Level:    m.level,
+			Lines:    m.lines,
+			Pieces:   m.pieces,
+			Seed:     m.seed,
+			Date:     time.Now(),
+			Name:     player,
+			Hinted:   hinted,
+			Practice: practice,
+		}
+		if hs.IsRankedOn(board, entry) {
+			m.isNewHS = !practice
+			m.rank = hs.AddTo(board, entry)
+			_ = hs.Save()
 		}
-		m.rank = hs.Add(entry)
-		_ = hs.Save()
 	}
 
 	return m
 }
 
+// timed reports whether the mode is ranked by time rather than score.
+func (m GameOverModel) timed() bool {
+	return m.mode == game.ModeSprint || m.mode == game.ModeCheese
+}
+
 // View renders the game over screen.
 func (m GameOverModel) View(s Styles) string {
 	t := s.Theme
 	var sb strings.Builder
 
+	heading := "GAME OVER"
+	if m.finished {
+		heading = strings.ToUpper(game.ModeName(m.mode)) + " COMPLETE"
+		if m.mode == game.ModeUltra {
+			heading = "TIME UP"
+		}
+	}
 	title := lipgloss.NewStyle().
 		Foreground(t.Main).
 		Bold(true).
-		Render("GAME OVER")
+		Render(heading)
 
 	sb.WriteString(title)
 	sb.WriteString("\n\n")
 
 	if m.isNewHS {
+		record := "NEW HIGH SCORE"
+		if m.timed() {
+			record = "NEW BEST TIME"
+		}
 		sb.WriteString(lipgloss.NewStyle().
 			Foreground(t.Main).
 			Bold(true).
-			Render(fmt.Sprintf("NEW HIGH SCORE #%d!", m.rank)))
+			Render(fmt.Sprintf("%s #%d!", record, m.rank)))
 		sb.WriteString("\n\n")
 	}
 
 	labelStyle := lipgloss.NewStyle().Foreground(t.Sub).Width(8)
 	valueStyle := lipgloss.NewStyle().Foreground(t.FG)
 
-	sb.WriteString(labelStyle.Render("Score") + valueStyle.Render(fmt.Sprintf("%d", m.score)))
-	sb.WriteString("\n")
-	sb.WriteString(labelStyle.Render("Level") + valueStyle.Render(fmt.Sprintf("%d", m.level)))
-	sb.WriteString("\n")
-	sb.WriteString(labelStyle.Render("Lines") + valueStyle.Render(fmt.Sprintf("%d", m.lines)))
-	sb.WriteString("\n")
+	if m.timed() {
+		sb.WriteString(labelStyle.Render("Time") + valueStyle.Render(formatMillis(m.elapsed)))
+		sb.WriteString("\n")
+		if m.mode == game.ModeCheese {
+			sb.WriteString(labelStyle.Render("Garbage") + valueStyle.Render(fmt.Sprintf("%d/%d", m.garbage, m.garbGoal)))
+		} else {
+			sb.WriteString(labelStyle.Render("Lines") + valueStyle.Render(fmt.Sprintf("%d/%d", m.lines, m.lineGoal)))
+		}
+		sb.WriteString("\n")
+	} else {
+		sb.WriteString(labelStyle.Render("Score") + valueStyle.Render(fmt.Sprintf("%d", m.score)))
+		sb.WriteString("\n")
+		sb.WriteString(labelStyle.Render("Level") + valueStyle.Render(fmt.Sprintf("%d", m.level)))
+		sb.WriteString("\n")
+		sb.WriteString(labelStyle.Render("Lines") + valueStyle.Render(fmt.Sprintf("%d", m.lines)))
+		sb.WriteString("\n")
+	}
 	sb.WriteString(labelStyle.Render("Pieces") + valueStyle.Render(fmt.Sprintf("%d", m.pieces)))
+	sb.WriteString("\n")
+	if m.finesse.Pieces > 0 {
+		sb.WriteString(labelStyle.Render("Finesse") + valueStyle.Render(fmt.Sprintf("%.1f%%", m.finesse.Percent())))
+		sb.WriteString(lipgloss.NewStyle().Foreground(t.Sub).Render(fmt.Sprintf("  %d faults", m.finesse.Faults)))
+		sb.WriteString("\n")
+	}
+	sb.WriteString(labelStyle.Render("Seed") + lipgloss.NewStyle().Foreground(t.Sub).Render(fmt.Sprintf("%d", m.seed)))
 	sb.WriteString("\n\n")
 
+	switch {
+	case m.practice:
+		sb.WriteString(lipgloss.NewStyle().
+			Foreground(t.Sub).
+			Render("Practice game: not ranked"))
+		sb.WriteString("\n\n")
+	case m.hinted:
+		sb.WriteString(lipgloss.NewStyle().
+			Foreground(t.Sub).
+			Render("Played with hints: ranked below unassisted games"))
+		sb.WriteString("\n\n")
+	}
+
 	dimStyle := lipgloss.NewStyle().Foreground(t.SubAlt)
 	sb.WriteString(dimStyle.Render("r restart  q menu"))
 
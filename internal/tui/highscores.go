@@ -3,74 +3,273 @@ package tui
 import (
 	"fmt"
 	"strings"
+	"unicode"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/meszmate/briks/internal/config"
+	"github.com/meszmate/briks/internal/game"
+	"github.com/meszmate/briks/internal/theme"
 )
 
-// HighScoresModel displays the high scores table.
+// leaderboard is one tab of the high scores screen.
+type leaderboard struct {
+	title string
+	// scoreBoard names a HighScores board; empty is the Marathon table.
+	scoreBoard string
+	// timeBoard names a BestTimes board; if set, scoreBoard is unused.
+	timeBoard string
+}
+
+var leaderboards = func() []leaderboard {
+	boards := []leaderboard{{title: "Marathon"}}
+	for _, lines := range game.SprintGoals {
+		boards = append(boards, leaderboard{
+			title:     fmt.Sprintf("Sprint %d", lines),
+			timeBoard: config.SprintBoard(lines),
+		})
+	}
+	for _, lines := range game.CheeseGoals {
+		boards = append(boards, leaderboard{
+			title:     fmt.Sprintf("Cheese %d", lines),
+			timeBoard: config.CheeseBoard(lines),
+		})
+	}
+	for _, secs := range game.UltraDurations {
+		boards = append(boards, leaderboard{
+			title:      "Ultra " + formatSeconds(secs),
+			scoreBoard: config.UltraBoard(secs),
+		})
+	}
+	return boards
+}()
+
+// HighScoresModel displays the high scores and best times tables of one
+// board size and rotation system.
 type HighScoresModel struct {
-	scores *config.HighScores
+	scores        *config.HighScores
+	times         *config.BestTimes
+	width, height int
+	rotation      string
+	tab           int
+}
+
+// NewHighScoresModel creates a high scores model showing the tables of
+// games played with the configured board size and rotation system.
+func NewHighScoresModel(hs *config.HighScores, bt *config.BestTimes, cfg *config.Config, s Styles) HighScoresModel {
+	return HighScoresModel{scores: hs, times: bt, width: cfg.BoardWidth, height: cfg.BoardHeight, rotation: cfg.Rotation}
+}
+
+// board returns the name of a table for the variant shown.
+func (m HighScoresModel) board(name string) string {
+	return config.VariantBoard(name, m.width, m.height, m.rotation)
 }
 
-// NewHighScoresModel creates a new high scores model.
-func NewHighScoresModel(hs *config.HighScores, s Styles) HighScoresModel {
-	return HighScoresModel{scores: hs}
+// Update switches between leaderboards.
+func (m HighScoresModel) Update(msg tea.KeyMsg) HighScoresModel {
+	switch msg.String() {
+	case "l", "right", "tab":
+		m.tab = (m.tab + 1) % len(leaderboards)
+	case "h", "left", "shift+tab":
+		m.tab = (m.tab - 1 + len(leaderboards)) % len(leaderboards)
+	}
+	return m
 }
 
-// View renders the high scores table.
+// View renders the selected leaderboard.
 func (m HighScoresModel) View(s Styles) string {
 	t := s.Theme
 	var sb strings.Builder
 
+	heading := "HIGH SCORES"
+	if m.width != game.DefaultWidth || m.height != game.DefaultHeight {
+		heading += fmt.Sprintf(" %dx%d", m.width, m.height)
+	}
+	if m.rotation != game.SRS.Name() {
+		heading += " " + m.rotation
+	}
 	title := lipgloss.NewStyle().
 		Foreground(t.Main).
 		Bold(true).
-		Render("HIGH SCORES")
+		Render(heading)
 
 	sb.WriteString(title)
 	sb.WriteString("\n\n")
 
-	if len(m.scores.Scores) == 0 {
+	// Tabs
+	for i, lb := range leaderboards {
+		if i == m.tab {
+			sb.WriteString(lipgloss.NewStyle().Foreground(t.Main).Bold(true).Render("[" + lb.title + "]"))
+		} else {
+			sb.WriteString(lipgloss.NewStyle().Foreground(t.Sub).Render(" " + lb.title + " "))
+		}
+		sb.WriteString(" ")
+	}
+	sb.WriteString("\n\n")
+
+	if lb := leaderboards[m.tab]; lb.timeBoard != "" {
+		m.viewTimes(&sb, s, m.board(lb.timeBoard))
+	} else {
+		m.viewScores(&sb, s, m.board(lb.scoreBoard))
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(lipgloss.NewStyle().
+		Foreground(t.SubAlt).
+		Render("   h/l switch table  q back"))
+
+	return sb.String()
+}
+
+func (m HighScoresModel) viewScores(sb *strings.Builder, s Styles, board string) {
+	t := s.Theme
+
+	scores := m.scores.Board(board)
+	if len(scores) == 0 {
 		sb.WriteString(lipgloss.NewStyle().
 			Foreground(t.Sub).
 			Render("No scores yet. Play a game!"))
-	} else {
-		// Header
-		headerStyle := lipgloss.NewStyle().Foreground(t.Sub)
-		sb.WriteString(headerStyle.Render(fmt.Sprintf("   %-4s %10s %6s %6s   %s", "#", "Score", "Level", "Lines", "Date")))
-		sb.WriteString("\n")
-		sb.WriteString(lipgloss.NewStyle().Foreground(t.SubAlt).Render("   " + strings.Repeat("─", 42)))
 		sb.WriteString("\n")
+		return
+	}
+
+	named := false
+	for _, hs := range scores {
+		named = named || hs.Name != ""
+	}
+
+	// Header
+	headerStyle := lipgloss.NewStyle().Foreground(t.Sub)
+	sb.WriteString(headerStyle.Render(fmt.Sprintf("   %-4s %10s %6s %6s   %s", "#", "Score", "Level", "Lines", "Date")))
+	rule := 42
+	if named {
+		sb.WriteString(headerStyle.Render(fmt.Sprintf("   %-*s", nameWidth, "Player")))
+		rule += 3 + nameWidth
+	}
+	sb.WriteString("\n")
+	sb.WriteString(lipgloss.NewStyle().Foreground(t.SubAlt).Render("   " + strings.Repeat("─", rule)))
+	sb.WriteString("\n")
 
-		for i, hs := range m.scores.Scores {
-			dateStr := hs.Date.Format("2006-01-02")
-
-			var rankStr string
-			rankStyle := lipgloss.NewStyle().Foreground(t.Sub)
-			scoreStyle := lipgloss.NewStyle().Foreground(t.FG)
-
-			if i == 0 {
-				rankStyle = rankStyle.Foreground(t.Main).Bold(true)
-				scoreStyle = scoreStyle.Foreground(t.Main).Bold(true)
-				rankStr = " 1."
-			} else {
-				rankStr = fmt.Sprintf("%2d.", i+1)
-			}
-
-			sb.WriteString(rankStyle.Render(fmt.Sprintf("   %-4s", rankStr)))
-			sb.WriteString(scoreStyle.Render(fmt.Sprintf("%10d", hs.Score)))
-			sb.WriteString(lipgloss.NewStyle().Foreground(t.FG).Render(fmt.Sprintf(" %6d", hs.Level)))
-			sb.WriteString(lipgloss.NewStyle().Foreground(t.FG).Render(fmt.Sprintf(" %6d", hs.Lines)))
-			sb.WriteString(lipgloss.NewStyle().Foreground(t.Sub).Render(fmt.Sprintf("   %s", dateStr)))
-			sb.WriteString("\n")
+	for i, hs := range scores {
+		dateStr := hs.Date.Format("2006-01-02")
+		rankStyle, valueStyle := rankStyles(t, i)
+
+		sb.WriteString(rankStyle.Render(fmt.Sprintf("   %-4s", entryRank(i, hs.Practice))))
+		sb.WriteString(valueStyle.Render(fmt.Sprintf("%10d", hs.Score)))
+		sb.WriteString(lipgloss.NewStyle().Foreground(t.FG).Render(fmt.Sprintf(" %6d", hs.Level)))
+		sb.WriteString(lipgloss.NewStyle().Foreground(t.FG).Render(fmt.Sprintf(" %6d", hs.Lines)))
+		sb.WriteString(lipgloss.NewStyle().Foreground(t.Sub).Render(fmt.Sprintf("   %s", dateStr)))
+		if named {
+			sb.WriteString(lipgloss.NewStyle().Foreground(t.FG).Render("   " + playerName(hs.Name)))
+		}
+		if note := entryNote(hs.Hinted, hs.Practice); note != "" {
+			sb.WriteString(lipgloss.NewStyle().Foreground(t.SubAlt).Render("   " + note))
 		}
+		sb.WriteString("\n")
+	}
+}
+
+func (m HighScoresModel) viewTimes(sb *strings.Builder, s Styles, board string) {
+	t := s.Theme
+
+	times := m.times.Board(board)
+	if len(times) == 0 {
+		sb.WriteString(lipgloss.NewStyle().
+			Foreground(t.Sub).
+			Render("No times yet. Finish a run!"))
+		sb.WriteString("\n")
+		return
+	}
+
+	named := false
+	for _, bt := range times {
+		named = named || bt.Name != ""
 	}
 
+	headerStyle := lipgloss.NewStyle().Foreground(t.Sub)
+	sb.WriteString(headerStyle.Render(fmt.Sprintf("   %-4s %10s %6s   %s", "#", "Time", "Pieces", "Date")))
+	rule := 35
+	if named {
+		sb.WriteString(headerStyle.Render(fmt.Sprintf("   %-*s", nameWidth, "Player")))
+		rule += 3 + nameWidth
+	}
+	sb.WriteString("\n")
+	sb.WriteString(lipgloss.NewStyle().Foreground(t.SubAlt).Render("   " + strings.Repeat("─", rule)))
 	sb.WriteString("\n")
-	sb.WriteString(lipgloss.NewStyle().
-		Foreground(t.SubAlt).
-		Render("   q back"))
 
-	return sb.String()
+	for i, bt := range times {
+		rankStyle, valueStyle := rankStyles(t, i)
+
+		sb.WriteString(rankStyle.Render(fmt.Sprintf("   %-4s", entryRank(i, bt.Practice))))
+		sb.WriteString(valueStyle.Render(fmt.Sprintf("%10s", formatMillis(bt.Time))))
+		sb.WriteString(lipgloss.NewStyle().Foreground(t.FG).Render(fmt.Sprintf(" %6d", bt.Pieces)))
+		sb.WriteString(lipgloss.NewStyle().Foreground(t.Sub).Render(fmt.Sprintf("   %s", bt.Date.Format("2006-01-02"))))
+		if named {
+			sb.WriteString(lipgloss.NewStyle().Foreground(t.FG).Render("   " + playerName(bt.Name)))
+		}
+		if note := entryNote(bt.Hinted, bt.Practice); note != "" {
+			sb.WriteString(lipgloss.NewStyle().Foreground(t.SubAlt).Render("   " + note))
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// nameWidth is the width of the player column, shown on shared servers.
+const nameWidth = 12
+
+// playerName fits a player name into the player column.
+func playerName(name string) string {
+	name = cleanName(name)
+	if r := []rune(name); len(r) > nameWidth {
+		return string(r[:nameWidth-1]) + "…"
+	}
+	return name
+}
+
+// cleanName keeps only the printable characters of a player name. Names
+// come from SSH clients and are shown on other players' terminals, so
+// they must not carry escape sequences.
+func cleanName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsPrint(r) {
+			return r
+		}
+		return -1
+	}, name)
+}
+
+// rankStyles returns the rank and value styles for a table row; the top
+// entry is highlighted.
+func rankStyles(t theme.Theme, i int) (lipgloss.Style, lipgloss.Style) {
+	rankStyle := lipgloss.NewStyle().Foreground(t.Sub)
+	valueStyle := lipgloss.NewStyle().Foreground(t.FG)
+	if i == 0 {
+		rankStyle = rankStyle.Foreground(t.Main).Bold(true)
+		valueStyle = valueStyle.Foreground(t.Main).Bold(true)
+	}
+	return rankStyle, valueStyle
+}
+
+func rankLabel(i int) string {
+	return fmt.Sprintf("%2d.", i+1)
+}
+
+// entryRank is the rank column of a board entry; practice games have none.
+func entryRank(i int, practice bool) string {
+	if practice {
+		return " -"
+	}
+	return rankLabel(i)
+}
+
+// entryNote marks a board entry that was not played unassisted.
+func entryNote(hinted, practice bool) string {
+	switch {
+	case practice:
+		return "practice, unranked"
+	case hinted:
+		return "hinted"
+	default:
+		return ""
+	}
 }
@@ -0,0 +1,27 @@
+package tui
+
+import "testing"
+
+func TestPlayerName(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"alice", "alice"},
+		{"bob\x1b]0;pwned\x07", "bob]0;pwned"},
+		{"\x1b[2J\x1b[Heve", "[2J[Heve"},
+		{"tab\tnew\nline\u009b", "tabnewline"},
+		{"josé 名前", "josé 名前"},
+		{"averyveryverylongname", "averyveryve…"},
+	}
+	for _, tt := range tests {
+		if got := playerName(tt.name); got != tt.want {
+			t.Errorf("playerName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	var a App
+	a.SetPlayer("mallory\x1b[31m")
+	if a.player != "mallory[31m" {
+		t.Errorf("SetPlayer kept %q", a.player)
+	}
+}
@@ -0,0 +1,34 @@
+package tui
+
+import (
+	"github.com/meszmate/briks/internal/ai"
+	"github.com/meszmate/briks/internal/game"
+)
+
+// hinter suggests where to place the current piece, using the built-in
+// AI. The suggestion is worked out once per piece.
+type hinter struct {
+	bot *ai.Bot
+	// current is the piece the hint is for; every spawn and hold makes a
+	// new one.
+	current *game.Piece
+	hint    ai.Placement
+	ok      bool
+}
+
+func newHinter() *hinter {
+	return &hinter{bot: ai.New(nil)}
+}
+
+// suggest returns the placement recommended for the engine's current
+// piece; Hold reports whether to hold first.
+func (h *hinter) suggest(e *game.Engine) (ai.Placement, bool) {
+	if e.Current == nil || e.Over() {
+		return ai.Placement{}, false
+	}
+	if e.Current != h.current {
+		h.current = e.Current
+		h.hint, h.ok = h.bot.Best(ai.StateOf(e))
+	}
+	return h.hint, h.ok
+}
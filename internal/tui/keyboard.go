@@ -0,0 +1,168 @@
+package tui
+
+import (
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Kitty keyboard protocol flags: disambiguate escape codes (1), report
+// event types (2) and report all keys as escape codes (8). The last one is
+// needed because release events are never sent for plain text keys.
+const (
+	keyboardPush = "\x1b[>11u"
+	keyboardPop  = "\x1b[<u"
+)
+
+// enableKeyboardEnhancements asks the terminal to report key releases via
+// the kitty keyboard protocol. Terminals that don't support it ignore the
+// request and keep sending legacy input.
+func enableKeyboardEnhancements(w io.Writer) tea.Cmd {
+	return func() tea.Msg {
+		_, _ = io.WriteString(w, keyboardPush)
+		return nil
+	}
+}
+
+// ResetKeyboard undoes enableKeyboardEnhancements. It is safe to call on
+// terminals that never enabled it.
+func ResetKeyboard(w io.Writer) {
+	_, _ = io.WriteString(w, keyboardPop)
+}
+
+// translateKeyboardMsg converts kitty keyboard protocol sequences into
+// regular key messages and KeyReleaseMsg. Bubble Tea v1 does not parse
+// these itself and hands them over as unknown CSI sequences.
+func translateKeyboardMsg(msg tea.Msg) (tea.Msg, bool) {
+	seq, ok := csiBytes(msg)
+	if !ok {
+		return nil, false
+	}
+	return parseKittyKey(string(seq[2:]))
+}
+
+// csiBytes returns the raw bytes of a CSI sequence Bubble Tea couldn't
+// parse, including the leading "\x1b[". Bubble Tea keeps those in an
+// unexported []byte type, so the message is matched by its kind and
+// contents rather than by the type's name.
+func csiBytes(msg tea.Msg) ([]byte, bool) {
+	v := reflect.ValueOf(msg)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.Uint8 {
+		return nil, false
+	}
+	seq := v.Bytes()
+	if len(seq) < 3 || seq[0] != '\x1b' || seq[1] != '[' {
+		return nil, false
+	}
+	return seq, true
+}
+
+// parseKittyKey parses the body of a "CSI code;mods:event u" sequence or
+// one of the legacy-style "CSI 1;mods:event A" functional key sequences.
+func parseKittyKey(body string) (tea.Msg, bool) {
+	if body == "" {
+		return nil, false
+	}
+	final := body[len(body)-1]
+	params := strings.Split(body[:len(body)-1], ";")
+
+	// Key code, ignoring alternate key codes after a colon.
+	code := 1
+	if params[0] != "" {
+		c, err := strconv.Atoi(strings.SplitN(params[0], ":", 2)[0])
+		if err != nil {
+			return nil, false
+		}
+		code = c
+	}
+
+	mods, event := 0, 1
+	if len(params) > 1 {
+		parts := strings.SplitN(params[1], ":", 2)
+		if m, err := strconv.Atoi(parts[0]); err == nil && m > 0 {
+			mods = m - 1
+		}
+		if len(parts) == 2 {
+			if ev, err := strconv.Atoi(parts[1]); err == nil {
+				event = ev
+			}
+		}
+	}
+	shift, alt, ctrl := mods&1 != 0, mods&2 != 0, mods&4 != 0
+
+	var key tea.Key
+	switch final {
+	case 'u':
+		k, ok := kittyCodeKey(code, shift, ctrl)
+		if !ok {
+			return nil, false
+		}
+		key = k
+	case 'A':
+		key.Type = tea.KeyUp
+	case 'B':
+		key.Type = tea.KeyDown
+	case 'C':
+		key.Type = tea.KeyRight
+	case 'D':
+		key.Type = tea.KeyLeft
+	case 'H':
+		key.Type = tea.KeyHome
+	case 'F':
+		key.Type = tea.KeyEnd
+	case '~':
+		switch code {
+		case 2:
+			key.Type = tea.KeyInsert
+		case 3:
+			key.Type = tea.KeyDelete
+		case 5:
+			key.Type = tea.KeyPgUp
+		case 6:
+			key.Type = tea.KeyPgDown
+		default:
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+	key.Alt = alt
+
+	// 1 = press, 2 = repeat, 3 = release.
+	if event == 3 {
+		return KeyReleaseMsg{Key: tea.KeyMsg(key).String()}, true
+	}
+	return tea.KeyMsg(key), true
+}
+
+func kittyCodeKey(code int, shift, ctrl bool) (tea.Key, bool) {
+	switch code {
+	case 9:
+		return tea.Key{Type: tea.KeyTab}, true
+	case 13:
+		return tea.Key{Type: tea.KeyEnter}, true
+	case 27:
+		return tea.Key{Type: tea.KeyEsc}, true
+	case 32:
+		return tea.Key{Type: tea.KeySpace, Runes: []rune{' '}}, true
+	case 127:
+		return tea.Key{Type: tea.KeyBackspace}, true
+	}
+
+	r := rune(code)
+	// Codes in the private use area are modifier and media keys.
+	if r < 32 || (r >= 0xe000 && r <= 0xf8ff) || !unicode.IsPrint(r) {
+		return tea.Key{}, false
+	}
+	if ctrl && r >= 'a' && r <= 'z' {
+		return tea.Key{Type: tea.KeyCtrlA + tea.KeyType(r-'a')}, true
+	}
+	if shift {
+		r = unicode.ToUpper(r)
+	}
+	return tea.Key{Type: tea.KeyRunes, Runes: []rune{r}}, true
+}
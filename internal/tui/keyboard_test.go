@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keyRecorder keeps the key presses and releases it receives, after
+// translation, and quits after want of them.
+type keyRecorder struct {
+	want int
+	got  []string
+}
+
+func (m *keyRecorder) Init() tea.Cmd { return nil }
+
+func (m *keyRecorder) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if t, ok := translateKeyboardMsg(msg); ok {
+		msg = t
+	}
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		m.got = append(m.got, "press "+msg.String())
+	case KeyReleaseMsg:
+		m.got = append(m.got, "release "+msg.Key)
+	}
+	if len(m.got) == m.want {
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m *keyRecorder) View() string { return "" }
+
+// TestTranslateKeyboardMsg runs kitty sequences through Bubble Tea itself,
+// so an upgrade that changes how it hands them over fails here.
+func TestTranslateKeyboardMsg(t *testing.T) {
+	input := "\x1b[97u" + // a pressed
+		"\x1b[97;1:3u" + // a released
+		"\x1b[1;3:1A" + // alt+up pressed
+		"\x1b[1;1:3D" + // left released
+		"\x1b[13;1:3u" // enter released
+	want := []string{"press a", "release a", "press alt+up", "release left", "release enter"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	m := &keyRecorder{want: len(want)}
+	p := tea.NewProgram(m,
+		tea.WithContext(ctx),
+		tea.WithInput(strings.NewReader(input)),
+		tea.WithOutput(io.Discard),
+		tea.WithoutRenderer(),
+		tea.WithoutSignalHandler(),
+	)
+	if _, err := p.Run(); err != nil {
+		t.Fatalf("got %q before: %v", m.got, err)
+	}
+	if !slices.Equal(m.got, want) {
+		t.Errorf("got %q, want %q", m.got, want)
+	}
+}
+
+func TestTranslateKeyboardMsgIgnoresOthers(t *testing.T) {
+	for _, msg := range []tea.Msg{
+		tea.KeyMsg{Type: tea.KeyEnter},
+		FrameTickMsg{},
+		[]byte("97u"),
+		"\x1b[97u",
+	} {
+		if got, ok := translateKeyboardMsg(msg); ok {
+			t.Errorf("translated %#v to %#v", msg, got)
+		}
+	}
+}
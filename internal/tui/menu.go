@@ -6,37 +6,51 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
+// menuContinue is the menu item for continuing a suspended game, listed
+// first when there is one.
+const menuContinue = "Continue"
+
 var menuItems = []string{
 	"Play",
 	"Settings",
 	"High Scores",
+	"Replays",
+	"Demo",
 	"Key Bindings",
 	"Quit",
 }
 
 // MenuModel represents the main menu.
 type MenuModel struct {
+	items  []string
 	cursor int
+	// notice is shown under the items, such as why the last action
+	// failed.
+	notice string
 }
 
-// NewMenuModel creates a new menu.
-func NewMenuModel(s Styles) MenuModel {
-	return MenuModel{}
+// NewMenuModel creates a new menu, with a Continue item if there is a
+// suspended game.
+func NewMenuModel(s Styles, canContinue bool) MenuModel {
+	if canContinue {
+		return MenuModel{items: append([]string{menuContinue}, menuItems...)}
+	}
+	return MenuModel{items: menuItems}
 }
 
-// Selected returns the currently selected menu index.
-func (m *MenuModel) Selected() int {
-	return m.cursor
+// Selected returns the currently selected menu item.
+func (m *MenuModel) Selected() string {
+	return m.items[m.cursor]
 }
 
 // Next moves the cursor down.
 func (m *MenuModel) Next() {
-	m.cursor = (m.cursor + 1) % len(menuItems)
+	m.cursor = (m.cursor + 1) % len(m.items)
 }
 
 // Prev moves the cursor up.
 func (m *MenuModel) Prev() {
-	m.cursor = (m.cursor - 1 + len(menuItems)) % len(menuItems)
+	m.cursor = (m.cursor - 1 + len(m.items)) % len(m.items)
 }
 
 // View renders the menu.
@@ -57,7 +71,7 @@ func (m MenuModel) View(s Styles) string {
 	sb.WriteString("\n\n")
 
 	// Menu items
-	for i, item := range menuItems {
+	for i, item := range m.items {
 		if i == m.cursor {
 			sb.WriteString(lipgloss.NewStyle().
 				Foreground(t.Main).
@@ -72,6 +86,10 @@ func (m MenuModel) View(s Styles) string {
 	}
 
 	sb.WriteString("\n")
+	if m.notice != "" {
+		sb.WriteString(lipgloss.NewStyle().Foreground(t.PieceZ).Render("   " + m.notice))
+		sb.WriteString("\n\n")
+	}
 	sb.WriteString(lipgloss.NewStyle().
 		Foreground(t.SubAlt).
 		Render("   j/k navigate  enter select  q quit"))
@@ -1,18 +1,50 @@
 package tui
 
-import "time"
+import (
+	"time"
 
-// TickMsg is sent on every game gravity tick.
-type TickMsg struct {
+	"github.com/meszmate/briks/internal/game"
+	"github.com/meszmate/briks/internal/netplay"
+	"github.com/meszmate/briks/internal/tbp"
+)
+
+// RainbowTickMsg advances the rainbow theme animation.
+type RainbowTickMsg struct {
 	Time time.Time
 }
 
-// LockTickMsg checks lock delay expiration.
-type LockTickMsg struct {
+// FrameTickMsg advances the game clock and per-frame logic such as auto-shift.
+type FrameTickMsg struct {
 	Time time.Time
+	Loop int64
 }
 
-// RainbowTickMsg advances the rainbow theme animation.
-type RainbowTickMsg struct {
+// IdleTickMsg lets the main menu start the demo when it has been idle.
+type IdleTickMsg struct {
 	Time time.Time
 }
+
+// KeyReleaseMsg is sent when a key is released. Only terminals that
+// support the kitty keyboard protocol report releases.
+type KeyReleaseMsg struct {
+	Key string
+}
+
+// NetMsg delivers a message from an online opponent.
+type NetMsg struct {
+	netplay.Message
+}
+
+// WatchFrameMsg delivers the next state of a watched game, or why the
+// broadcast ended.
+type WatchFrameMsg struct {
+	State game.DisplayState
+	Err   error
+}
+
+// BotMovesMsg delivers the moves a TBP bot suggested, or why it failed.
+type BotMovesMsg struct {
+	pilot *autopilot
+	Moves []tbp.Move
+	Err   error
+}
@@ -0,0 +1,181 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/meszmate/briks/internal/config"
+	"github.com/meszmate/briks/internal/game"
+	"github.com/meszmate/briks/internal/tbp"
+)
+
+type modeItem struct {
+	mode game.Mode
+	desc string
+}
+
+var modeItems = []modeItem{
+	{game.ModeMarathon, "endless, play for score"},
+	{game.ModeSprint, "clear the lines as fast as possible"},
+	{game.ModeUltra, "score as much as possible before time runs out"},
+	{game.ModeCheese, "dig through messy garbage as fast as possible"},
+	{game.ModeVersus, "two players on one keyboard, send garbage to win"},
+	{game.ModeFinesse, "place single pieces on targets with the fewest keys"},
+}
+
+// ModeSelectModel lets the player pick a game mode and its variant.
+type ModeSelectModel struct {
+	cursor int
+	// bot, if set, is the versus opponent.
+	bot *tbp.Client
+}
+
+// NewModeSelectModel creates a mode selection model with the given mode
+// highlighted. bot is the versus opponent, if versus is against a bot.
+func NewModeSelectModel(selected game.Mode, bot *tbp.Client) ModeSelectModel {
+	m := ModeSelectModel{bot: bot}
+	for i, item := range modeItems {
+		if item.mode == selected {
+			m.cursor = i
+		}
+	}
+	return m
+}
+
+// Selected returns the highlighted mode.
+func (m ModeSelectModel) Selected() game.Mode {
+	return modeItems[m.cursor].mode
+}
+
+// Update handles navigation and variant changes.
+func (m ModeSelectModel) Update(msg tea.KeyMsg, cfg *config.Config) ModeSelectModel {
+	switch msg.String() {
+	case "j", "down":
+		m.cursor = (m.cursor + 1) % len(modeItems)
+	case "k", "up":
+		m.cursor = (m.cursor - 1 + len(modeItems)) % len(modeItems)
+	case "l", "right":
+		cycleVariant(m.Selected(), cfg, 1)
+	case "h", "left":
+		cycleVariant(m.Selected(), cfg, -1)
+	}
+	return m
+}
+
+// cycleVariant steps through the variants of a mode, stored in the config.
+func cycleVariant(mode game.Mode, cfg *config.Config, dir int) {
+	switch mode {
+	case game.ModeSprint:
+		cfg.SprintLines = cycleInt(game.SprintGoals, cfg.SprintLines, dir)
+	case game.ModeUltra:
+		cfg.UltraSeconds = cycleInt(game.UltraDurations, cfg.UltraSeconds, dir)
+	case game.ModeCheese:
+		cfg.CheeseLines = cycleInt(game.CheeseGoals, cfg.CheeseLines, dir)
+	case game.ModeVersus:
+		cfg.VersusRounds = cycleInt(game.VersusRounds, cfg.VersusRounds, dir)
+	}
+}
+
+// cycleInt returns the value dir steps away from cur in values, wrapping.
+func cycleInt(values []int, cur, dir int) int {
+	idx := 0
+	for i, v := range values {
+		if v == cur {
+			idx = i
+			break
+		}
+	}
+	return values[(idx+dir+len(values))%len(values)]
+}
+
+// variantLabel describes the selected variant of a mode, if it has any.
+func variantLabel(mode game.Mode, cfg *config.Config) string {
+	switch mode {
+	case game.ModeSprint:
+		return fmt.Sprintf("%d lines", cfg.SprintLines)
+	case game.ModeUltra:
+		return formatSeconds(cfg.UltraSeconds)
+	case game.ModeCheese:
+		return fmt.Sprintf("%d lines", cfg.CheeseLines)
+	case game.ModeVersus:
+		return versusVariantLabel(cfg.VersusRounds)
+	default:
+		return ""
+	}
+}
+
+// formatSeconds formats a whole number of seconds as m:ss.
+func formatSeconds(secs int) string {
+	return fmt.Sprintf("%d:%02d", secs/60, secs%60)
+}
+
+// gameOptions builds the engine options for a mode from the config.
+func gameOptions(mode game.Mode, cfg *config.Config, seed uint64) game.Options {
+	var opts game.Options
+	switch mode {
+	case game.ModeSprint:
+		opts = game.SprintOptions(cfg.SprintLines, cfg.PreviewCount, seed)
+	case game.ModeCheese:
+		opts = game.CheeseOptions(cfg.CheeseLines, cfg.PreviewCount, seed)
+	case game.ModeVersus:
+		opts = game.VersusOptions(cfg.PreviewCount, seed)
+	case game.ModeFinesse:
+		opts = game.FinesseOptions(cfg.PreviewCount, seed)
+	case game.ModeUltra:
+		opts = game.UltraOptions(time.Duration(cfg.UltraSeconds)*time.Second, cfg.PreviewCount, seed)
+	default:
+		opts = game.MarathonOptions(cfg.StartLevel, cfg.PreviewCount, seed)
+	}
+	opts.Width, opts.Height = cfg.BoardWidth, cfg.BoardHeight
+	opts.Rotation, _ = game.RotationSystemNamed(cfg.Rotation)
+	return opts
+}
+
+// View renders the mode list.
+func (m ModeSelectModel) View(s Styles, cfg *config.Config) string {
+	t := s.Theme
+	var sb strings.Builder
+
+	sb.WriteString(lipgloss.NewStyle().
+		Foreground(t.Main).
+		Bold(true).
+		Render("PLAY"))
+	sb.WriteString("\n\n")
+
+	for i, item := range modeItems {
+		name := game.ModeName(item.mode)
+		labelStyle := lipgloss.NewStyle().Width(12)
+		variant := variantLabel(item.mode, cfg)
+
+		if i == m.cursor {
+			sb.WriteString(lipgloss.NewStyle().Foreground(t.Main).Render(" > "))
+			sb.WriteString(labelStyle.Foreground(t.FG).Bold(true).Render(name))
+			if variant != "" {
+				sb.WriteString(lipgloss.NewStyle().Foreground(t.Main).Render("< " + variant + " >"))
+			}
+		} else {
+			sb.WriteString("   ")
+			sb.WriteString(labelStyle.Foreground(t.Sub).Render(name))
+			if variant != "" {
+				sb.WriteString(lipgloss.NewStyle().Foreground(t.Sub).Render("  " + variant))
+			}
+		}
+		sb.WriteString("\n")
+		desc := item.desc
+		if item.mode == game.ModeVersus && m.bot != nil {
+			desc = "play against " + m.bot.Name + ", send garbage to win"
+		}
+		sb.WriteString(lipgloss.NewStyle().Foreground(t.SubAlt).Render("     " + desc))
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(lipgloss.NewStyle().
+		Foreground(t.SubAlt).
+		Render("   j/k navigate  h/l variant  enter start  q back"))
+
+	return sb.String()
+}
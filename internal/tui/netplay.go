@@ -0,0 +1,177 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/meszmate/briks/internal/game"
+	"github.com/meszmate/briks/internal/netplay"
+)
+
+// waitNet waits for the next message from the opponent.
+func waitNet(s *netplay.Session) tea.Cmd {
+	return func() tea.Msg {
+		m, ok := <-s.Messages()
+		if !ok {
+			return nil
+		}
+		return NetMsg{m}
+	}
+}
+
+// StartNetplay makes the app open straight into an online versus match
+// over an established session. The host starts the first round at once;
+// the guest waits for it.
+func (a *App) StartNetplay(s *netplay.Session) {
+	a.session = s
+	a.mode = game.ModeVersus
+	a.match = NewVersusMatch(s.BestOf)
+	if s.Host {
+		m, _ := a.startNetRound()
+		*a = m.(App)
+	} else {
+		a.screen = ScreenNetWait
+	}
+}
+
+// leaveNetplay ends the online match, if any.
+func (a *App) leaveNetplay() {
+	if a.session != nil {
+		_ = a.session.Close()
+		a.session = nil
+	}
+}
+
+// newNetGame creates an online round with the given seed.
+func (a App) newNetGame(seed uint64) GameModel {
+	opts := game.VersusOptions(a.cfg.PreviewCount, seed)
+	return NewNetModel(a.cfg, a.keys, a.rainbow, opts, a.session)
+}
+
+// startNetRound starts a round on the host and tells the guest.
+func (a App) startNetRound() (tea.Model, tea.Cmd) {
+	seed := game.RandomSeed()
+	if a.seed != nil {
+		seed = *a.seed
+	}
+	if err := a.session.Start(seed); err != nil {
+		return a.abandonNet("Connection lost: " + err.Error())
+	}
+	a.game = a.newNetGame(seed)
+	a.screen = ScreenGame
+	return a, a.game.Init()
+}
+
+// endNetRound handles the local player topping out online. The host
+// decides the round at once; the guest reports it and waits.
+func (a App) endNetRound() (tea.Model, tea.Cmd) {
+	if !a.session.Host {
+		_ = a.session.Send(netplay.Message{Type: netplay.MsgTopOut})
+		return a, nil
+	}
+	return a.finishNetRound(1)
+}
+
+// finishNetRound shows the result of a round won by the given local
+// player index. The host announces it to the guest.
+func (a App) finishNetRound(winner int) (tea.Model, tea.Cmd) {
+	if a.session.Host {
+		_ = a.session.Send(netplay.Message{Type: netplay.MsgRound, Winner: winner})
+	}
+	a.result = NewVersusResultModel(a.game, &a.match, winner)
+	a.screen = ScreenVersusResult
+	return a, nil
+}
+
+// abandonNet ends the match after the connection dropped.
+func (a App) abandonNet(reason string) (tea.Model, tea.Cmd) {
+	a.leaveNetplay()
+	a.result = newAbandonedResult(a.game, a.match, reason)
+	a.screen = ScreenVersusResult
+	return a, nil
+}
+
+// updateNet handles a message from the opponent.
+func (a App) updateNet(m netplay.Message) (tea.Model, tea.Cmd) {
+	if a.session == nil {
+		return a, nil
+	}
+	next := waitNet(a.session)
+	inRound := a.screen == ScreenGame && a.game.session() != nil
+
+	switch m.Type {
+	case netplay.MsgStart:
+		if a.session.Host {
+			break
+		}
+		if a.match.Winner() >= 0 {
+			a.match = NewVersusMatch(a.match.BestOf)
+		}
+		a.game = a.newNetGame(m.Seed)
+		a.screen = ScreenGame
+		return a, tea.Batch(next, a.game.Init())
+
+	case netplay.MsgInput, netplay.MsgGarbage, netplay.MsgClock:
+		if a.game.mirror != nil {
+			a.game.mirror.Apply(m)
+		}
+
+	case netplay.MsgAttack:
+		if inRound && !a.game.gameOver {
+			a.game.players[0].receiveGarbage(m.Lines)
+		}
+
+	case netplay.MsgTopOut:
+		if a.session.Host && inRound && !a.game.gameOver {
+			model, cmd := a.finishNetRound(0)
+			return model, tea.Batch(next, cmd)
+		}
+
+	case netplay.MsgRound:
+		if !a.session.Host && inRound {
+			// The host is player 0 on its side and player 1 on ours.
+			winner := m.Winner
+			if winner >= 0 {
+				winner = 1 - winner
+			}
+			model, cmd := a.finishNetRound(winner)
+			return model, tea.Batch(next, cmd)
+		}
+
+	case netplay.MsgBye:
+		return a.abandonNet("Your opponent left the match.")
+
+	case netplay.MsgDisconnect:
+		return a.abandonNet("Connection lost: " + m.Error)
+	}
+	return a, next
+}
+
+func (a App) updateNetWait(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "q", "esc":
+			a.leaveNetplay()
+			a.screen = ScreenMenu
+			a.menu = a.newMenu()
+		}
+	}
+	return a, nil
+}
+
+// netWaitView renders the guest's screen before the first round.
+func netWaitView(s Styles) string {
+	t := s.Theme
+	var sb strings.Builder
+	sb.WriteString(lipgloss.NewStyle().Foreground(t.Main).Bold(true).Render("CONNECTED"))
+	sb.WriteString("\n\n")
+	sb.WriteString(lipgloss.NewStyle().Foreground(t.FG).Render("Waiting for the host to start the match…"))
+	sb.WriteString("\n\n")
+	sb.WriteString(lipgloss.NewStyle().Foreground(t.SubAlt).Render("q leave"))
+	return lipgloss.NewStyle().
+		Padding(1, 3).
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(t.SubAlt).
+		Render(sb.String())
+}
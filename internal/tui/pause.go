@@ -7,11 +7,14 @@ import (
 )
 
 // PauseModel represents the pause overlay.
-type PauseModel struct{}
+type PauseModel struct {
+	// saves is set when quitting keeps the game to be continued.
+	saves bool
+}
 
 // NewPauseModel creates a new pause model.
-func NewPauseModel() PauseModel {
-	return PauseModel{}
+func NewPauseModel(saves bool) PauseModel {
+	return PauseModel{saves: saves}
 }
 
 // View renders the pause screen.
@@ -34,7 +37,11 @@ func (p PauseModel) View(s Styles) string {
 	sb.WriteString("\n")
 	sb.WriteString(keyStyle.Render("r") + dimStyle.Render(" restart"))
 	sb.WriteString("\n")
-	sb.WriteString(keyStyle.Render("q") + dimStyle.Render(" quit"))
+	quit := " quit"
+	if p.saves {
+		quit = " save and quit"
+	}
+	sb.WriteString(keyStyle.Render("q") + dimStyle.Render(quit))
 
 	return lipgloss.NewStyle().
 		Padding(1, 3).
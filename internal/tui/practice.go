@@ -0,0 +1,76 @@
+package tui
+
+import "github.com/meszmate/briks/internal/game"
+
+// practice keeps the snapshots behind the practice actions: the last
+// pieces for undo, and one saved state.
+type practice struct {
+	depth int
+	// history holds the game as each of the last placed pieces spawned,
+	// oldest first, and spawn the game as the current piece spawned.
+	history []game.Snapshot
+	spawn   game.Snapshot
+	// placed is the engine's piece count when spawn was taken.
+	placed int
+	saved  *game.Snapshot
+	// used is set once undo or load state has changed the game.
+	used bool
+}
+
+func newPractice(e *game.Engine, depth int) *practice {
+	return &practice{depth: depth, spawn: e.Snapshot(), placed: e.PiecesPlaced}
+}
+
+// track notes a piece placed since the last call, remembering the game as
+// that piece spawned so it can be undone. It is called after every change
+// to the engine.
+func (pr *practice) track(e *game.Engine) {
+	if e.PiecesPlaced == pr.placed || e.State != game.StatePlaying {
+		return
+	}
+	pr.history = append(pr.history, pr.spawn)
+	if len(pr.history) > pr.depth {
+		pr.history = pr.history[len(pr.history)-pr.depth:]
+	}
+	pr.spawn = e.Snapshot()
+	pr.placed = e.PiecesPlaced
+}
+
+// undo takes back the last placed piece, reporting whether there was one.
+func (pr *practice) undo(e *game.Engine) bool {
+	if len(pr.history) == 0 {
+		return false
+	}
+	s := pr.history[len(pr.history)-1]
+	if err := e.Restore(s); err != nil {
+		return false
+	}
+	pr.history = pr.history[:len(pr.history)-1]
+	pr.spawn = s
+	pr.placed = e.PiecesPlaced
+	pr.used = true
+	return true
+}
+
+// save remembers the game as it is now.
+func (pr *practice) save(e *game.Engine) {
+	s := e.Snapshot()
+	pr.saved = &s
+}
+
+// load returns the game to the saved state, reporting whether there was
+// one. The undo history is dropped, since it belongs to another line of
+// play.
+func (pr *practice) load(e *game.Engine) bool {
+	if pr.saved == nil {
+		return false
+	}
+	if err := e.Restore(*pr.saved); err != nil {
+		return false
+	}
+	pr.history = nil
+	pr.spawn = *pr.saved
+	pr.placed = e.PiecesPlaced
+	pr.used = true
+	return true
+}
@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/meszmate/briks/internal/game"
+)
+
+// placePiece hard drops the current piece and lets pr see it.
+func placePiece(pr *practice, e *game.Engine) {
+	e.MoveLeft()
+	e.HardDrop()
+	pr.track(e)
+}
+
+func TestPracticeUndoDepth(t *testing.T) {
+	const depth = 3
+	e := game.NewEngine(game.MarathonOptions(1, 5, 4))
+	pr := newPractice(e, depth)
+
+	// spawns[i] is the game as the piece placed i-th spawned.
+	var spawns []game.Snapshot
+	for range depth + 2 {
+		spawns = append(spawns, e.Snapshot())
+		placePiece(pr, e)
+	}
+	if len(pr.history) != depth {
+		t.Fatalf("history holds %d pieces, want %d", len(pr.history), depth)
+	}
+
+	for i := range depth {
+		if !pr.undo(e) {
+			t.Fatalf("undo %d failed", i+1)
+		}
+		want := spawns[len(spawns)-1-i]
+		if got := e.Snapshot(); !reflect.DeepEqual(got, want) {
+			t.Errorf("undo %d: game at piece %d, want piece %d", i+1, got.PiecesPlaced, want.PiecesPlaced)
+		}
+	}
+	if pr.undo(e) {
+		t.Error("undid more pieces than the undo depth")
+	}
+	if !pr.used {
+		t.Error("undo didn't mark the game as practice")
+	}
+
+	// Pieces placed after an undo can be undone in turn.
+	placePiece(pr, e)
+	if !pr.undo(e) || !reflect.DeepEqual(e.Snapshot(), spawns[2]) {
+		t.Error("couldn't undo a piece placed after undoing")
+	}
+}
+
+func TestPracticeLoadState(t *testing.T) {
+	e := game.NewEngine(game.MarathonOptions(1, 5, 4))
+	pr := newPractice(e, 10)
+	if pr.load(e) {
+		t.Error("loaded a state that was never saved")
+	}
+
+	placePiece(pr, e)
+	e.Hold()
+	pr.save(e)
+	saved := e.Snapshot()
+	for range 4 {
+		placePiece(pr, e)
+	}
+
+	if !pr.load(e) {
+		t.Fatal("load failed")
+	}
+	if got := e.Snapshot(); !reflect.DeepEqual(got, saved) {
+		t.Errorf("loaded game at piece %d, want the saved one at %d", got.PiecesPlaced, saved.PiecesPlaced)
+	}
+	if pr.undo(e) {
+		t.Error("undid a piece from before the state was loaded")
+	}
+	if !pr.used {
+		t.Error("load didn't mark the game as practice")
+	}
+
+	// The saved state can be loaded again, and what follows it undone.
+	placePiece(pr, e)
+	if !pr.undo(e) || !reflect.DeepEqual(e.Snapshot(), saved) {
+		t.Error("couldn't undo back to the loaded state")
+	}
+	placePiece(pr, e)
+	if !pr.load(e) || !reflect.DeepEqual(e.Snapshot(), saved) {
+		t.Error("couldn't load the saved state a second time")
+	}
+}
@@ -3,6 +3,7 @@ package tui
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/meszmate/briks/internal/game"
@@ -13,23 +14,38 @@ const (
 	// Block characters for pieces (foreground colored, no background)
 	blockFull  = "██"
 	blockGhost = "░░"
+	blockHint  = "[]"
 	blockEmpty = "  "
 	blockGrid  = "· "
 )
 
-// RenderBoard renders the visible portion of the board with the active and ghost pieces.
-func RenderBoard(engine *game.Engine, styles Styles, showGhost, showGrid bool, rainbow *theme.RainbowState) string {
+// Overlay is optional information drawn on a playfield.
+type Overlay struct {
+	// Outline marks where the current piece should go: a placement hint
+	// or a finesse trainer target.
+	Outline *game.Piece
+	// Flash highlights cells, such as a piece placed with a finesse fault.
+	Flash []game.Position
+	// Finesse, if set, is shown in the stats panel.
+	Finesse *game.FinesseStats
+}
+
+// RenderBoard renders the visible portion of the board with the active and ghost pieces,
+// and the outline and flashing cells of the overlay.
+func RenderBoard(engine *game.Engine, styles Styles, showGhost, showGrid bool, overlay Overlay, rainbow *theme.RainbowState) string {
 	t := styles.Theme
 
 	// Build a visible grid with colors.
 	type cell struct {
 		color   lipgloss.Color
 		isGhost bool
+		isHint  bool
 	}
-	grid := make([][]cell, game.VisibleRows)
-	for r := 0; r < game.VisibleRows; r++ {
-		grid[r] = make([]cell, game.BoardWidth)
-		for c := 0; c < game.BoardWidth; c++ {
+	width, height := engine.Board.Width, engine.Board.Height
+	grid := make([][]cell, height)
+	for r := 0; r < height; r++ {
+		grid[r] = make([]cell, width)
+		for c := 0; c < width; c++ {
 			cellColor := engine.Board.GetVisibleCell(r, c)
 			if cellColor != game.Empty {
 				grid[r][c] = cell{color: pieceColorToLipgloss(cellColor, t, rainbow)}
@@ -43,7 +59,7 @@ func RenderBoard(engine *game.Engine, styles Styles, showGhost, showGrid bool, r
 		ghostColor := pieceColorToLipgloss(game.PieceColor(engine.Current.Type), t, rainbow)
 		for _, gc := range ghostCells {
 			vr := gc.Row - game.BufferRows
-			if vr >= 0 && vr < game.VisibleRows && gc.Col >= 0 && gc.Col < game.BoardWidth {
+			if vr >= 0 && vr < height && gc.Col >= 0 && gc.Col < width {
 				if grid[vr][gc.Col].color == "" {
 					grid[vr][gc.Col] = cell{color: ghostColor, isGhost: true}
 				}
@@ -51,13 +67,33 @@ func RenderBoard(engine *game.Engine, styles Styles, showGhost, showGrid bool, r
 		}
 	}
 
+	// Draw the outline over the ghost, so it stays visible when they agree.
+	if hint := overlay.Outline; hint != nil {
+		hintColor := pieceColorToLipgloss(game.PieceColor(hint.Type), t, rainbow)
+		for _, hc := range engine.Board.PieceCells(hint) {
+			vr := hc.Row - game.BufferRows
+			if vr >= 0 && vr < height && hc.Col >= 0 && hc.Col < width {
+				if grid[vr][hc.Col].color == "" || grid[vr][hc.Col].isGhost {
+					grid[vr][hc.Col] = cell{color: hintColor, isHint: true}
+				}
+			}
+		}
+	}
+
+	for _, fc := range overlay.Flash {
+		vr := fc.Row - game.BufferRows
+		if vr >= 0 && vr < height && fc.Col >= 0 && fc.Col < width {
+			grid[vr][fc.Col] = cell{color: t.FG}
+		}
+	}
+
 	// Draw current piece.
 	if engine.Current != nil {
-		cells := engine.Current.Cells()
+		cells := engine.Board.PieceCells(engine.Current)
 		color := pieceColorToLipgloss(game.PieceColor(engine.Current.Type), t, rainbow)
 		for _, c := range cells {
 			vr := c.Row - game.BufferRows
-			if vr >= 0 && vr < game.VisibleRows && c.Col >= 0 && c.Col < game.BoardWidth {
+			if vr >= 0 && vr < height && c.Col >= 0 && c.Col < width {
 				grid[vr][c.Col] = cell{color: color, isGhost: false}
 			}
 		}
@@ -68,16 +104,18 @@ func RenderBoard(engine *game.Engine, styles Styles, showGhost, showGrid bool, r
 	borderStyle := lipgloss.NewStyle().Foreground(t.Sub)
 
 	// Top border.
-	sb.WriteString(borderStyle.Render("┌" + strings.Repeat("──", game.BoardWidth) + "┐"))
+	sb.WriteString(borderStyle.Render("┌" + strings.Repeat("──", width) + "┐"))
 	sb.WriteString("\n")
 
-	for r := 0; r < game.VisibleRows; r++ {
+	for r := 0; r < height; r++ {
 		sb.WriteString(borderStyle.Render("│"))
-		for c := 0; c < game.BoardWidth; c++ {
+		for c := 0; c < width; c++ {
 			if grid[r][c].color != "" {
 				style := lipgloss.NewStyle().Foreground(grid[r][c].color)
 				if grid[r][c].isGhost {
 					sb.WriteString(style.Render(blockGhost))
+				} else if grid[r][c].isHint {
+					sb.WriteString(style.Render(blockHint))
 				} else {
 					sb.WriteString(style.Render(blockFull))
 				}
@@ -92,14 +130,35 @@ func RenderBoard(engine *game.Engine, styles Styles, showGhost, showGrid bool, r
 	}
 
 	// Bottom border.
-	sb.WriteString(borderStyle.Render("└" + strings.Repeat("──", game.BoardWidth) + "┘"))
+	sb.WriteString(borderStyle.Render("└" + strings.Repeat("──", width) + "┘"))
 
 	return sb.String()
 }
 
-// RenderPiecePreview renders a small preview of a piece type.
-func RenderPiecePreview(pt game.PieceType, t theme.Theme, rainbow *theme.RainbowState) string {
-	offsets := game.PieceRotations[pt][game.Rot0]
+// RenderGarbageMeter renders a one-column bar, as tall as a board of the
+// given height, that fills from the bottom with the pending incoming
+// garbage.
+func RenderGarbageMeter(pending, height int, styles Styles) string {
+	fill := lipgloss.NewStyle().Foreground(styles.Theme.PieceZ)
+	var sb strings.Builder
+	// Blank line beside the top border.
+	sb.WriteString(" \n")
+	for r := 0; r < height; r++ {
+		if height-r <= pending {
+			sb.WriteString(fill.Render("▐"))
+		} else {
+			sb.WriteString(" ")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(" ")
+	return sb.String()
+}
+
+// RenderPiecePreview renders a small preview of a piece type as it spawns
+// in a rotation system.
+func RenderPiecePreview(pt game.PieceType, rs game.RotationSystem, t theme.Theme, rainbow *theme.RainbowState) string {
+	offsets := rs.Shape(pt, game.Rot0)
 	color := pieceColorToLipgloss(game.PieceColor(pt), t, rainbow)
 
 	// Find bounding box.
@@ -148,7 +207,7 @@ func RenderPiecePreview(pt game.PieceType, t theme.Theme, rainbow *theme.Rainbow
 }
 
 // RenderHoldPanel renders the hold piece panel.
-func RenderHoldPanel(holdPiece *game.PieceType, holdUsed bool, styles Styles, rainbow *theme.RainbowState) string {
+func RenderHoldPanel(holdPiece *game.PieceType, holdUsed bool, rs game.RotationSystem, styles Styles, rainbow *theme.RainbowState) string {
 	t := styles.Theme
 	var sb strings.Builder
 
@@ -161,7 +220,7 @@ func RenderHoldPanel(holdPiece *game.PieceType, holdUsed bool, styles Styles, ra
 	sb.WriteString("\n")
 
 	if holdPiece != nil {
-		preview := RenderPiecePreview(*holdPiece, t, rainbow)
+		preview := RenderPiecePreview(*holdPiece, rs, t, rainbow)
 		lines := strings.Split(preview, "\n")
 
 		// Center the piece in the box (4 cells wide = 8 chars)
@@ -198,7 +257,7 @@ func RenderHoldPanel(holdPiece *game.PieceType, holdUsed bool, styles Styles, ra
 }
 
 // RenderNextPanel renders the next pieces preview panel.
-func RenderNextPanel(pieces []game.PieceType, styles Styles, rainbow *theme.RainbowState) string {
+func RenderNextPanel(pieces []game.PieceType, rs game.RotationSystem, styles Styles, rainbow *theme.RainbowState) string {
 	t := styles.Theme
 	var sb strings.Builder
 
@@ -211,7 +270,7 @@ func RenderNextPanel(pieces []game.PieceType, styles Styles, rainbow *theme.Rain
 	sb.WriteString("\n")
 
 	for i, pt := range pieces {
-		preview := RenderPiecePreview(pt, t, rainbow)
+		preview := RenderPiecePreview(pt, rs, t, rainbow)
 		lines := strings.Split(preview, "\n")
 		for j := 0; j < 2; j++ {
 			sb.WriteString(borderStyle.Render("│"))
@@ -239,9 +298,14 @@ func RenderNextPanel(pieces []game.PieceType, styles Styles, rainbow *theme.Rain
 	return sb.String()
 }
 
-// RenderStatsPanel renders the score/level/lines panel.
-func RenderStatsPanel(scorer *game.Scorer, styles Styles) string {
+// ultraWarning is when the time-limit countdown starts flashing.
+const ultraWarning = 10 * time.Second
+
+// RenderStatsPanel renders the score/level/lines panel, with the finesse
+// faults if finesse is set.
+func RenderStatsPanel(engine *game.Engine, styles Styles, finesse *game.FinesseStats) string {
 	t := styles.Theme
+	scorer := engine.Scorer
 	var sb strings.Builder
 
 	labelStyle := lipgloss.NewStyle().Foreground(t.Sub)
@@ -260,7 +324,49 @@ func RenderStatsPanel(scorer *game.Scorer, styles Styles) string {
 
 	sb.WriteString(labelStyle.Render("LINES"))
 	sb.WriteString("\n")
-	sb.WriteString(valueStyle.Render(fmt.Sprintf("%d", scorer.Lines)))
+	if engine.LineGoal > 0 {
+		sb.WriteString(valueStyle.Render(fmt.Sprintf("%d/%d", scorer.Lines, engine.LineGoal)))
+	} else {
+		sb.WriteString(valueStyle.Render(fmt.Sprintf("%d", scorer.Lines)))
+	}
+
+	if engine.GarbageGoal > 0 {
+		sb.WriteString("\n\n")
+		sb.WriteString(labelStyle.Render("GARBAGE"))
+		sb.WriteString("\n")
+		sb.WriteString(valueStyle.Render(fmt.Sprintf("%d/%d", engine.GarbageCleared(), engine.GarbageGoal)))
+	}
+
+	if engine.Mode == game.ModeSprint || engine.Mode == game.ModeCheese {
+		sb.WriteString("\n\n")
+		sb.WriteString(labelStyle.Render("TIME"))
+		sb.WriteString("\n")
+		sb.WriteString(highlightStyle.Render(formatMillis(engine.ElapsedTime())))
+	}
+
+	if engine.TimeLimit > 0 {
+		left := engine.TimeLeft()
+		timeStyle := highlightStyle
+		if left <= ultraWarning {
+			// Flash the countdown twice a second in the final seconds.
+			timeStyle = timeStyle.Foreground(t.PieceZ)
+			if left%time.Second < time.Second/2 {
+				timeStyle = timeStyle.Foreground(t.FG)
+			}
+		}
+		sb.WriteString("\n\n")
+		sb.WriteString(labelStyle.Render("TIME LEFT"))
+		sb.WriteString("\n")
+		sb.WriteString(timeStyle.Render(formatClock(left)))
+	}
+
+	if finesse != nil {
+		sb.WriteString("\n\n")
+		sb.WriteString(labelStyle.Render("FAULTS"))
+		sb.WriteString("\n")
+		sb.WriteString(valueStyle.Render(fmt.Sprintf("%d", finesse.Faults)))
+		sb.WriteString(labelStyle.Render(fmt.Sprintf(" %.0f%%", finesse.Percent())))
+	}
 
 	if scorer.Combo > 1 {
 		sb.WriteString("\n\n")
@@ -277,6 +383,53 @@ func RenderStatsPanel(scorer *game.Scorer, styles Styles) string {
 	return sb.String()
 }
 
+// calloutDuration is how long a clear callout stays on screen.
+const calloutDuration = 2 * time.Second
+
+// RenderCallout renders a short-lived announcement of the last special
+// clear, or an empty string when there is nothing to show.
+func RenderCallout(engine *game.Engine, styles Styles) string {
+	last := engine.LastClear
+	if last.Type == game.ClearNone || engine.Clock-last.Time >= calloutDuration {
+		return ""
+	}
+
+	var lines []string
+	if last.BackToBack {
+		lines = append(lines, "B2B")
+	}
+	// Plain singles to triples aren't worth announcing.
+	if last.Type == game.ClearTetris || last.Type >= game.ClearTSpinSingle {
+		// Split before the line count so names fit the side panel.
+		name := strings.ToUpper(game.ClearName(last.Type))
+		if i := strings.LastIndexByte(name, ' '); i > 0 {
+			name = name[:i] + "\n" + name[i+1:]
+		}
+		lines = append(lines, name)
+	}
+	if last.PerfectClear {
+		lines = append(lines, "PERFECT\nCLEAR")
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return lipgloss.NewStyle().
+		Foreground(styles.Theme.Main).
+		Bold(true).
+		Render(strings.Join(lines, "\n"))
+}
+
+// formatClock formats a duration as m:ss.t.
+func formatClock(d time.Duration) string {
+	d = d.Truncate(100 * time.Millisecond)
+	return fmt.Sprintf("%d:%02d.%d", int(d.Minutes()), int(d.Seconds())%60, int(d.Milliseconds()/100)%10)
+}
+
+// formatMillis formats a duration as m:ss.mmm.
+func formatMillis(d time.Duration) string {
+	return fmt.Sprintf("%d:%02d.%03d", int(d.Minutes()), int(d.Seconds())%60, d.Milliseconds()%1000)
+}
+
 func pieceColorToLipgloss(c game.CellColor, t theme.Theme, rainbow *theme.RainbowState) lipgloss.Color {
 	if rainbow != nil && t.Name == "rainbow" {
 		switch c {
@@ -312,6 +465,8 @@ func pieceColorToLipgloss(c game.CellColor, t theme.Theme, rainbow *theme.Rainbo
 		return t.PieceJ
 	case game.ColorL:
 		return t.PieceL
+	case game.ColorGarbage:
+		return t.Sub
 	default:
 		return t.FG
 	}
@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/meszmate/briks/internal/config"
+	"github.com/meszmate/briks/internal/replay"
+	"github.com/meszmate/briks/internal/theme"
+)
+
+var replaySpeeds = []float64{0.25, 0.5, 1, 2, 4, 8}
+
+// replayNormalSpeed is the index of 1x in replaySpeeds.
+const replayNormalSpeed = 2
+
+// ReplayModel plays back a recorded game.
+type ReplayModel struct {
+	replay    *replay.Replay
+	player    *replay.Player
+	speed     int
+	paused    bool
+	loop      int64
+	lastFrame time.Time
+	exit      bool
+}
+
+// NewReplayModel creates a playback model positioned at the start.
+func NewReplayModel(r *replay.Replay) ReplayModel {
+	return ReplayModel{
+		replay: r,
+		player: replay.NewPlayer(r),
+		speed:  replayNormalSpeed,
+		loop:   newFrameLoop(),
+	}
+}
+
+// Init starts the playback frame loop.
+func (m ReplayModel) Init() tea.Cmd {
+	return frameTick(m.loop)
+}
+
+// Update handles playback input and frame ticks.
+func (m ReplayModel) Update(msg tea.Msg, rainbow *theme.RainbowState) (ReplayModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case FrameTickMsg:
+		if msg.Loop != m.loop {
+			return m, nil
+		}
+		var dt time.Duration
+		if !m.lastFrame.IsZero() {
+			dt = min(msg.Time.Sub(m.lastFrame), maxFrameDelta)
+		}
+		m.lastFrame = msg.Time
+		if !m.paused && !m.player.Done() {
+			m.seekBy(time.Duration(float64(dt) * replaySpeeds[m.speed]))
+		}
+		if rainbow != nil {
+			rainbow.Tick(dt.Seconds())
+		}
+		return m, frameTick(m.loop)
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc":
+			m.exit = true
+		case "p", " ":
+			m.paused = !m.paused
+		case "l", "right", "+", "=":
+			m.speed = min(m.speed+1, len(replaySpeeds)-1)
+		case "h", "left", "-":
+			m.speed = max(m.speed-1, 0)
+		case ".":
+			// Frame step.
+			m.paused = true
+			m.seekBy(frameInterval)
+		case "r":
+			m.player = replay.NewPlayer(m.replay)
+		}
+	}
+	return m, nil
+}
+
+func (m ReplayModel) seekBy(dt time.Duration) {
+	m.player.SeekForward(m.player.Engine.Clock + dt)
+}
+
+// View renders the replayed game with a playback status line.
+func (m ReplayModel) View(s Styles, cfg *config.Config, rainbow *theme.RainbowState) string {
+	t := s.Theme
+	dim := lipgloss.NewStyle().Foreground(t.SubAlt)
+	accent := lipgloss.NewStyle().Foreground(t.Main).Bold(true)
+
+	state := "▶"
+	if m.paused {
+		state = "❚❚"
+	}
+	status := accent.Render(fmt.Sprintf("REPLAY %s %gx", state, replaySpeeds[m.speed])) +
+		dim.Render(fmt.Sprintf("  %s / %s",
+			formatClock(m.player.Engine.Clock), formatClock(m.replay.Duration)))
+
+	if m.player.Done() {
+		if m.player.Verified() {
+			status += accent.Render("  END ✓")
+		} else {
+			status += lipgloss.NewStyle().Foreground(t.PieceZ).Bold(true).Render("  END: DESYNC")
+		}
+	}
+
+	help := dim.Render("p pause  h/l speed  . step  r restart  q back")
+	return renderPlayfield(m.player.Engine, s, cfg, rainbow, Overlay{}, status+"\n"+help)
+}
@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/meszmate/briks/internal/game"
+	"github.com/meszmate/briks/internal/replay"
+)
+
+// replaysShown is how many replay rows fit on screen at once.
+const replaysShown = 12
+
+// ReplaysModel lists saved replays.
+type ReplaysModel struct {
+	entries []replay.Entry
+	cursor  int
+}
+
+// NewReplaysModel creates a replay list from the replays kept in the
+// player's data directory.
+func NewReplaysModel(dataDir string) ReplaysModel {
+	return ReplaysModel{entries: replay.List(dataDir)}
+}
+
+// Update handles list navigation.
+func (m ReplaysModel) Update(msg tea.KeyMsg) ReplaysModel {
+	if len(m.entries) == 0 {
+		return m
+	}
+	switch msg.String() {
+	case "j", "down":
+		m.cursor = (m.cursor + 1) % len(m.entries)
+	case "k", "up":
+		m.cursor = (m.cursor - 1 + len(m.entries)) % len(m.entries)
+	}
+	return m
+}
+
+// Selected returns the highlighted replay, if any.
+func (m ReplaysModel) Selected() (replay.Entry, bool) {
+	if len(m.entries) == 0 {
+		return replay.Entry{}, false
+	}
+	return m.entries[m.cursor], true
+}
+
+// View renders the replay list.
+func (m ReplaysModel) View(s Styles) string {
+	t := s.Theme
+	var sb strings.Builder
+
+	sb.WriteString(lipgloss.NewStyle().
+		Foreground(t.Main).
+		Bold(true).
+		Render("REPLAYS"))
+	sb.WriteString("\n\n")
+
+	if len(m.entries) == 0 {
+		sb.WriteString(lipgloss.NewStyle().
+			Foreground(t.Sub).
+			Render("No replays yet. Finish a game!"))
+	} else {
+		headerStyle := lipgloss.NewStyle().Foreground(t.Sub)
+		sb.WriteString(headerStyle.Render(fmt.Sprintf("   %-16s %-11s %10s %6s %8s", "Date", "Mode", "Score", "Lines", "Time")))
+		sb.WriteString("\n")
+		sb.WriteString(lipgloss.NewStyle().Foreground(t.SubAlt).Render("   " + strings.Repeat("─", 55)))
+		sb.WriteString("\n")
+
+		// Scroll so the cursor stays visible.
+		start := 0
+		if m.cursor >= replaysShown {
+			start = m.cursor - replaysShown + 1
+		}
+		end := min(start+replaysShown, len(m.entries))
+
+		for i := start; i < end; i++ {
+			h := m.entries[i].Header
+			row := fmt.Sprintf("%-16s %-11s %10d %6d %8s",
+				h.Date.Format("2006-01-02 15:04"), game.ModeName(h.Mode), h.Score, h.Lines, formatClock(h.Duration))
+			if i == m.cursor {
+				sb.WriteString(lipgloss.NewStyle().Foreground(t.Main).Bold(true).Render(" > " + row))
+			} else {
+				sb.WriteString(lipgloss.NewStyle().Foreground(t.FG).Render("   " + row))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(lipgloss.NewStyle().
+		Foreground(t.SubAlt).
+		Render("   j/k navigate  enter play  q back"))
+
+	return sb.String()
+}
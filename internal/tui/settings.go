@@ -7,6 +7,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/meszmate/briks/internal/config"
+	"github.com/meszmate/briks/internal/game"
 	"github.com/meszmate/briks/internal/theme"
 )
 
@@ -20,9 +21,18 @@ var settingItems = []settingItem{
 	{"Start Level", "start_level"},
 	{"Ghost Piece", "ghost_piece"},
 	{"Show Grid", "show_grid"},
+	{"Hints", "hints"},
+	{"Practice", "practice"},
+	{"Undo Depth", "undo_depth"},
 	{"Preview Count", "preview_count"},
+	{"Board", "board"},
+	{"Board Width", "board_width"},
+	{"Board Height", "board_height"},
+	{"Rotation", "rotation"},
 	{"DAS (ms)", "das"},
 	{"ARR (ms)", "arr"},
+	{"P1 Keys", "p1_keys"},
+	{"P2 Keys", "p2_keys"},
 }
 
 // SettingsModel handles the settings screen.
@@ -79,6 +89,18 @@ func (m *SettingsModel) cycleValue(cfg *config.Config, dir int) {
 		cfg.GhostPiece = !cfg.GhostPiece
 	case "show_grid":
 		cfg.ShowGrid = !cfg.ShowGrid
+	case "hints":
+		cfg.Hints = !cfg.Hints
+	case "practice":
+		cfg.Practice = !cfg.Practice
+	case "undo_depth":
+		cfg.UndoDepth += dir
+		if cfg.UndoDepth < 1 {
+			cfg.UndoDepth = 50
+		}
+		if cfg.UndoDepth > 50 {
+			cfg.UndoDepth = 1
+		}
 	case "preview_count":
 		cfg.PreviewCount += dir
 		if cfg.PreviewCount < 1 {
@@ -87,6 +109,36 @@ func (m *SettingsModel) cycleValue(cfg *config.Config, dir int) {
 		if cfg.PreviewCount > 5 {
 			cfg.PreviewCount = 1
 		}
+	case "board":
+		idx := boardPreset(cfg)
+		if idx < 0 && dir < 0 {
+			// From a custom size, left goes to the last preset.
+			idx = 0
+		}
+		p := game.BoardPresets[(idx+dir+len(game.BoardPresets))%len(game.BoardPresets)]
+		cfg.BoardWidth, cfg.BoardHeight = p.Width, p.Height
+	case "board_width":
+		cfg.BoardWidth += dir
+		if cfg.BoardWidth < game.MinWidth {
+			cfg.BoardWidth = game.MaxWidth
+		}
+		if cfg.BoardWidth > game.MaxWidth {
+			cfg.BoardWidth = game.MinWidth
+		}
+	case "board_height":
+		cfg.BoardHeight += dir
+		if cfg.BoardHeight < game.MinHeight {
+			cfg.BoardHeight = game.MaxHeight
+		}
+		if cfg.BoardHeight > game.MaxHeight {
+			cfg.BoardHeight = game.MinHeight
+		}
+	case "rotation":
+		names := make([]string, len(game.RotationSystems))
+		for i, rs := range game.RotationSystems {
+			names[i] = rs.Name()
+		}
+		cfg.Rotation = cycleString(names, cfg.Rotation, dir)
 	case "das":
 		cfg.DAS += dir * 10
 		if cfg.DAS < 50 {
@@ -103,7 +155,34 @@ func (m *SettingsModel) cycleValue(cfg *config.Config, dir int) {
 		if cfg.ARR > 200 {
 			cfg.ARR = 200
 		}
+	case "p1_keys":
+		cfg.P1Keys = cycleString(config.KeyProfiles, cfg.P1Keys, dir)
+	case "p2_keys":
+		cfg.P2Keys = cycleString(config.KeyProfiles, cfg.P2Keys, dir)
+	}
+}
+
+// boardPreset returns the index of the board preset the configured size
+// matches, or -1 for a custom size.
+func boardPreset(cfg *config.Config) int {
+	for i, p := range game.BoardPresets {
+		if p.Width == cfg.BoardWidth && p.Height == cfg.BoardHeight {
+			return i
+		}
 	}
+	return -1
+}
+
+// cycleString returns the value dir steps away from cur in values, wrapping.
+func cycleString(values []string, cur string, dir int) string {
+	idx := 0
+	for i, v := range values {
+		if v == cur {
+			idx = i
+			break
+		}
+	}
+	return values[(idx+dir+len(values))%len(values)]
 }
 
 func getValue(cfg *config.Config, key string) string {
@@ -122,12 +201,39 @@ func getValue(cfg *config.Config, key string) string {
 			return "on"
 		}
 		return "off"
+	case "hints":
+		if cfg.Hints {
+			return "on"
+		}
+		return "off"
+	case "practice":
+		if cfg.Practice {
+			return "on"
+		}
+		return "off"
+	case "undo_depth":
+		return fmt.Sprintf("%d", cfg.UndoDepth)
 	case "preview_count":
 		return fmt.Sprintf("%d", cfg.PreviewCount)
+	case "board":
+		if i := boardPreset(cfg); i >= 0 {
+			return game.BoardPresets[i].Name
+		}
+		return "custom"
+	case "board_width":
+		return fmt.Sprintf("%d", cfg.BoardWidth)
+	case "board_height":
+		return fmt.Sprintf("%d", cfg.BoardHeight)
+	case "rotation":
+		return cfg.Rotation
 	case "das":
 		return fmt.Sprintf("%d", cfg.DAS)
 	case "arr":
 		return fmt.Sprintf("%d", cfg.ARR)
+	case "p1_keys":
+		return cfg.P1Keys
+	case "p2_keys":
+		return cfg.P2Keys
 	default:
 		return ""
 	}
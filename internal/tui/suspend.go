@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/meszmate/briks/internal/config"
+	"github.com/meszmate/briks/internal/game"
+	"github.com/meszmate/briks/internal/replay"
+	"github.com/meszmate/briks/internal/suspend"
+	"github.com/meszmate/briks/internal/theme"
+)
+
+// suspended returns the game to keep when the player quits in the middle
+// of it, or nil if it can't be continued later: versus and online games
+// are played out or lost.
+func (g GameModel) suspended() *suspend.Game {
+	if g.versus() || g.session() != nil || g.engine().State != game.StatePlaying {
+		return nil
+	}
+	p := g.players[0]
+	s := &suspend.Game{
+		Version:  suspend.Version,
+		Date:     time.Now(),
+		Engine:   p.engine.Snapshot(),
+		Hinted:   g.hinted(),
+		Practice: g.practiced(),
+		Finesse:  g.finesse(),
+	}
+	if t := p.finesse.train; t != nil {
+		s.Hits, s.Misses = t.hits, t.misses
+	}
+	if p.recorder != nil {
+		var buf bytes.Buffer
+		if err := replay.Encode(&buf, p.recorder.Finish()); err == nil {
+			s.Replay = buf.Bytes()
+		}
+	}
+	return s
+}
+
+// ResumeGameModel creates a gameplay model that continues a suspended
+// game. Practice undo starts over with an empty history.
+func ResumeGameModel(cfg *config.Config, keys *config.KeyBindings, rainbow *theme.RainbowState, s *suspend.Game) (GameModel, error) {
+	g := NewGameModel(cfg, keys, rainbow, s.Engine.Options())
+	p := g.players[0]
+	if err := p.engine.Restore(s.Engine); err != nil {
+		return GameModel{}, err
+	}
+
+	p.recorder = nil
+	if len(s.Replay) > 0 {
+		if r, err := replay.Decode(bytes.NewReader(s.Replay)); err == nil {
+			p.recorder = replay.ResumeRecorder(p.engine, r)
+		}
+	}
+	p.finesse.stats = s.Finesse
+	if t := p.finesse.train; t != nil {
+		t.hits, t.misses = s.Hits, s.Misses
+		t.spawned(p.engine.Board, p.engine.Current.Type)
+	}
+	if s.Hinted && g.hints == nil {
+		g.hints = newHinter()
+	}
+	if g.practice != nil {
+		g.practice = newPractice(p.engine, cfg.UndoDepth)
+	}
+	g.wasPractice = s.Practice
+	return g, nil
+}
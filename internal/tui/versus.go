@@ -0,0 +1,194 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/meszmate/briks/internal/game"
+)
+
+// versusMinWidth returns the terminal width needed for two playfields of
+// board b's size.
+func versusMinWidth(b *game.Board) int {
+	return 2*(2*b.Width+30) + 6
+}
+
+// VersusMatch tracks the rounds of a best-of-N versus match.
+type VersusMatch struct {
+	BestOf int
+	Wins   [2]int
+	Draws  int
+}
+
+// NewVersusMatch starts a best-of-n match.
+func NewVersusMatch(bestOf int) VersusMatch {
+	return VersusMatch{BestOf: bestOf}
+}
+
+// Record counts a round won by the given player, or a draw for -1.
+func (m *VersusMatch) Record(winner int) {
+	if winner < 0 {
+		m.Draws++
+		return
+	}
+	m.Wins[winner]++
+}
+
+// Winner returns the player who has won the match, or -1 while it is
+// still being played.
+func (m VersusMatch) Winner() int {
+	need := m.BestOf/2 + 1
+	for i, w := range m.Wins {
+		if w >= need {
+			return i
+		}
+	}
+	return -1
+}
+
+// Round returns the number of rounds played.
+func (m VersusMatch) Round() int {
+	return m.Wins[0] + m.Wins[1] + m.Draws
+}
+
+// versusStats is one player's result for a round.
+type versusStats struct {
+	score  int
+	lines  int
+	pieces int
+	sent   int
+}
+
+// VersusResultModel shows the outcome of a versus round and the match.
+type VersusResultModel struct {
+	match  VersusMatch
+	winner int
+	names  [2]string
+	stats  [2]versusStats
+	// guest is set on the joining side of an online match, which waits
+	// for the host to start rounds.
+	guest bool
+	// disconnected explains why an online match ended early.
+	disconnected string
+}
+
+// NewVersusResultModel records a finished round won by the given player
+// (-1 for a draw) in the match and creates its result screen.
+func NewVersusResultModel(g GameModel, match *VersusMatch, winner int) VersusResultModel {
+	match.Record(winner)
+	m := VersusResultModel{
+		match:  *match,
+		winner: winner,
+		guest:  g.session() != nil && !g.session().Host,
+	}
+	for i, p := range g.players {
+		m.names[i] = p.name
+		m.stats[i] = versusStats{
+			score:  p.engine.Scorer.Score,
+			lines:  p.engine.Scorer.Lines,
+			pieces: p.engine.PiecesPlaced,
+			sent:   p.sent,
+		}
+	}
+	return m
+}
+
+// newAbandonedResult creates the result screen of an online match that
+// ended because the connection did, without counting the round.
+func newAbandonedResult(g GameModel, match VersusMatch, reason string) VersusResultModel {
+	m := VersusResultModel{match: match, winner: -1, disconnected: reason}
+	for i, p := range g.players {
+		m.names[i] = p.name
+		m.stats[i] = versusStats{
+			score:  p.engine.Scorer.Score,
+			lines:  p.engine.Scorer.Lines,
+			pieces: p.engine.PiecesPlaced,
+			sent:   p.sent,
+		}
+	}
+	return m
+}
+
+// MatchOver reports whether the match has been decided or abandoned.
+func (m VersusResultModel) MatchOver() bool {
+	return m.match.Winner() >= 0 || m.disconnected != ""
+}
+
+// View renders the round result.
+func (m VersusResultModel) View(s Styles) string {
+	t := s.Theme
+	var sb strings.Builder
+	accent := lipgloss.NewStyle().Foreground(t.Main).Bold(true)
+
+	heading := fmt.Sprintf("ROUND %d: DRAW", m.match.Round())
+	if m.winner >= 0 {
+		heading = fmt.Sprintf("ROUND %d: %s WINS", m.match.Round(), m.names[m.winner])
+	}
+	if w := m.match.Winner(); w >= 0 {
+		heading = fmt.Sprintf("%s WINS THE MATCH", m.names[w])
+	}
+	if m.disconnected != "" {
+		heading = "MATCH ABANDONED"
+	}
+	sb.WriteString(accent.Render(heading))
+	sb.WriteString("\n\n")
+	if m.disconnected != "" {
+		sb.WriteString(lipgloss.NewStyle().Foreground(t.PieceZ).Render(m.disconnected))
+		sb.WriteString("\n\n")
+	}
+
+	sb.WriteString(lipgloss.NewStyle().Foreground(t.FG).Bold(true).Render(
+		fmt.Sprintf("%s  %d - %d  %s", m.names[0], m.match.Wins[0], m.match.Wins[1], m.names[1])))
+	sb.WriteString(lipgloss.NewStyle().Foreground(t.Sub).Render(
+		fmt.Sprintf("   best of %d", m.match.BestOf)))
+	sb.WriteString("\n\n")
+
+	labelStyle := lipgloss.NewStyle().Foreground(t.Sub).Width(8)
+	valueStyle := lipgloss.NewStyle().Foreground(t.FG).Width(10).Align(lipgloss.Right)
+	sb.WriteString(labelStyle.Render("") + valueStyle.Render(m.names[0]) + valueStyle.Render(m.names[1]))
+	sb.WriteString("\n")
+	rows := []struct {
+		label string
+		value func(versusStats) int
+	}{
+		{"Score", func(v versusStats) int { return v.score }},
+		{"Lines", func(v versusStats) int { return v.lines }},
+		{"Sent", func(v versusStats) int { return v.sent }},
+		{"Pieces", func(v versusStats) int { return v.pieces }},
+	}
+	for _, row := range rows {
+		sb.WriteString(labelStyle.Render(row.label))
+		for _, st := range m.stats {
+			sb.WriteString(valueStyle.Render(fmt.Sprintf("%d", row.value(st))))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+
+	dimStyle := lipgloss.NewStyle().Foreground(t.SubAlt)
+	switch {
+	case m.disconnected != "":
+		sb.WriteString(dimStyle.Render("q menu"))
+	case m.guest:
+		sb.WriteString(dimStyle.Render("waiting for the host…  q leave"))
+	case m.MatchOver():
+		sb.WriteString(dimStyle.Render("r rematch  q menu"))
+	default:
+		sb.WriteString(dimStyle.Render("enter next round  q menu"))
+	}
+
+	return lipgloss.NewStyle().
+		Padding(1, 3).
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(t.SubAlt).
+		Render(sb.String())
+}
+
+// versusVariantLabel describes a best-of-N setting.
+func versusVariantLabel(bestOf int) string {
+	if bestOf == 1 {
+		return "1 round"
+	}
+	return fmt.Sprintf("best of %d", bestOf)
+}
@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"errors"
+	"io"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/meszmate/briks/internal/config"
+	"github.com/meszmate/briks/internal/game"
+	"github.com/meszmate/briks/internal/spectate"
+	"github.com/meszmate/briks/internal/theme"
+)
+
+// WatchModel shows a game broadcast by another player.
+type WatchModel struct {
+	watcher *spectate.Watcher
+	addr    string
+	// engine shows the last state received; nil before the first.
+	engine *game.Engine
+	// err is why the broadcast ended, if it has.
+	err error
+}
+
+// NewWatchModel creates a model following the game behind w.
+func NewWatchModel(w *spectate.Watcher, addr string) WatchModel {
+	return WatchModel{watcher: w, addr: addr}
+}
+
+// Init starts waiting for the first frame.
+func (m WatchModel) Init() tea.Cmd {
+	return m.next()
+}
+
+func (m WatchModel) next() tea.Cmd {
+	w := m.watcher
+	return func() tea.Msg {
+		s, err := w.Next()
+		return WatchFrameMsg{State: s, Err: err}
+	}
+}
+
+// Update applies received frames.
+func (m WatchModel) Update(msg tea.Msg) (WatchModel, tea.Cmd) {
+	if msg, ok := msg.(WatchFrameMsg); ok {
+		if msg.Err != nil {
+			m.err = msg.Err
+			return m, nil
+		}
+		m.engine = game.NewDisplayEngine(msg.State)
+		return m, m.next()
+	}
+	return m, nil
+}
+
+// View renders the watched game with the local theme and settings.
+func (m WatchModel) View(s Styles, cfg *config.Config, rainbow *theme.RainbowState) string {
+	t := s.Theme
+	dim := lipgloss.NewStyle().Foreground(t.SubAlt)
+	accent := lipgloss.NewStyle().Foreground(t.Main).Bold(true)
+
+	status := accent.Render("WATCHING") + dim.Render("  "+m.addr)
+	switch {
+	case m.err != nil:
+		reason := m.err.Error()
+		if errors.Is(m.err, io.EOF) {
+			reason = "the player closed the game"
+		}
+		status = lipgloss.NewStyle().Foreground(t.PieceZ).Bold(true).Render("BROADCAST ENDED") +
+			dim.Render("  "+reason)
+	case m.engine != nil && m.engine.State == game.StateGameOver:
+		status += accent.Render("  GAME OVER")
+	case m.engine != nil && m.engine.State == game.StateFinished:
+		status += accent.Render("  " + game.ModeName(m.engine.Mode) + " COMPLETE")
+	}
+	help := dim.Render("q quit")
+
+	if m.engine == nil {
+		return lipgloss.JoinVertical(lipgloss.Center,
+			status, "", dim.Render("Waiting for the game to start…"), "", help)
+	}
+	return renderPlayfield(m.engine, s, cfg, rainbow, Overlay{}, status+"\n"+help)
+}